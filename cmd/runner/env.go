@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/example/go-test-framework/framework/env"
+	"github.com/example/go-test-framework/framework/suite"
+)
+
+// runEnv implements `runner env render --format k8s|compose --suite <id>`,
+// which prints deployable artifacts for a suite's declared environment.
+func runEnv(args []string, loader *suite.Loader, stdout, stderr io.Writer) error {
+	if len(args) == 0 || args[0] != "render" {
+		return errors.New("usage: runner env render --format k8s|compose --suite <id> [--root-password pw] [--output file]")
+	}
+	fs := flag.NewFlagSet("env render", flag.ContinueOnError)
+	format := fs.String("format", "k8s", "output format: k8s or compose")
+	suiteID := fs.String("suite", "", "id of the suite whose environment is rendered")
+	output := fs.String("output", "", "write to this file instead of stdout")
+	rootPassword := fs.String("root-password", "", "password of the postgres and mongodb root users (default derived from the suite id)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *suiteID == "" {
+		return errors.New("env render: --suite is required")
+	}
+
+	suites, err := loader.LoadTestSuites()
+	if err != nil {
+		return fmt.Errorf("loading suites failed: %w", err)
+	}
+	var ts *suite.TestSuite
+	ids := make([]string, 0, len(suites))
+	for _, s := range suites {
+		ids = append(ids, s.ID)
+		if s.ID == *suiteID {
+			ts = s
+		}
+	}
+	if ts == nil {
+		sort.Strings(ids)
+		return fmt.Errorf("env render: unknown suite %q (known: %v)", *suiteID, ids)
+	}
+	if ts.Environment.IsEmpty() {
+		return fmt.Errorf("env render: suite %q declares no environment", ts.ID)
+	}
+
+	var data []byte
+	switch *format {
+	case "k8s", "kubernetes":
+		data, err = env.RenderKubernetes(ts.ID, ts.Environment, *rootPassword)
+	case "compose":
+		data, err = env.RenderCompose(ts.ID, ts.Environment, *rootPassword)
+	default:
+		return fmt.Errorf("env render: unknown format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+	if ts.Environment.Postgres != nil || ts.Environment.Mongo != nil {
+		password := *rootPassword
+		if password == "" {
+			password = env.RootPassword(ts.ID)
+		}
+		fmt.Fprintf(stderr, "root password (postgres user postgres, mongodb user root): %s\n", password)
+	}
+	if *output != "" {
+		return os.WriteFile(*output, data, 0o600)
+	}
+	_, err = stdout.Write(data)
+	return err
+}
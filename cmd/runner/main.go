@@ -2,39 +2,112 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
+	"os"
 	"path/filepath"
 
 	_ "github.com/example/go-test-framework/suites"
 
+	"github.com/example/go-test-framework/framework/db/pool"
 	"github.com/example/go-test-framework/framework/declarative"
+	"github.com/example/go-test-framework/framework/env"
 	"github.com/example/go-test-framework/framework/executor"
 	httpclient "github.com/example/go-test-framework/framework/http"
+	"github.com/example/go-test-framework/framework/report"
+	"github.com/example/go-test-framework/framework/result"
 	"github.com/example/go-test-framework/framework/runner"
 	"github.com/example/go-test-framework/framework/suite"
 	"github.com/example/go-test-framework/framework/utils"
 )
 
 func main() {
-	ctx := context.Background()
 	workDir := filepath.Join(".", "work")
 	loader := suite.NewLoader(workDir)
-	suites := loader.LoadTestSuites()
+	loader.SuiteDir = filepath.Join(".", "suites")
+
+	if len(os.Args) > 1 && os.Args[1] == "env" {
+		if err := runEnv(os.Args[2:], loader, os.Stdout, os.Stderr); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	junitPath := flag.String("junit", "", "write a JUnit XML report to this path")
+	htmlPath := flag.String("html", "", "write a self-contained HTML report to this path")
+	continueOnFailure := flag.Bool("continue-on-failure", false, "run every test and suite instead of stopping at the first failure")
+	concurrency := flag.Int("concurrency", 0, "maximum tests running at once in a parallel suite (0 = unlimited)")
+	suiteConcurrency := flag.Int("suite-concurrency", 1, "number of suites to run in parallel")
+	provision := flag.String("provision", "", "provision suite environments: existing, docker or empty to skip")
+	existing := &env.Existing{}
+	flag.StringVar(&existing.PostgresAddr, "postgres-addr", "localhost:5432", "postgres address for -provision=existing")
+	flag.StringVar(&existing.MongoAddr, "mongo-addr", "localhost:27017", "mongodb address for -provision=existing")
+	flag.StringVar(&existing.MongoUser, "mongo-user", "", "mongodb user for suites without declared databases, e.g. root of a rendered environment")
+	flag.StringVar(&existing.MongoPassword, "mongo-password", "", "password of -mongo-user")
+	flag.StringVar(&existing.RedisAddr, "redis-addr", "localhost:6379", "redis address for -provision=existing")
+	flag.StringVar(&existing.RabbitMQAddr, "rabbitmq-addr", "localhost:5672", "rabbitmq address for -provision=existing")
+	var databases pool.Templates
+	flag.StringVar(&databases.Postgres, "postgres-dsn", "", "postgres DSN template with ${database}, ${username} and ${password} filled from the suite environment")
+	flag.StringVar(&databases.Mongo, "mongo-uri", "", "mongodb URI template with ${database}, ${username} and ${password} filled from the suite environment")
+	flag.StringVar(&databases.Redis, "redis-url", "", "redis address or redis:// URL used by redis assertions")
+	flag.StringVar(&databases.RabbitMQ, "rabbitmq-url", "", "amqp:// URL used by publish steps and message assertions")
+	flag.StringVar(&databases.Kafka, "kafka-brokers", "", "comma-separated Kafka brokers used by produce steps and record assertions")
+	flag.Parse()
+
+	ctx := context.Background()
+	suites, err := loader.LoadTestSuites()
+	if err != nil {
+		log.Fatalf("loading suites failed: %v", err)
+	}
 
 	resolver := httpclient.StaticResolver{
 		"bonus-service":    "http://localhost:8081",
 		"payments-service": "http://localhost:8082",
 	}
+	if err := suite.Validate(suites, resolver); err != nil {
+		log.Fatalf("invalid suites: %v", err)
+	}
 
 	declExec := &declarative.Executor{
-		HTTP:   httpclient.New(resolver),
-		Logger: utils.NewLogger(),
-		// Postgres and Mongo clients should be wired using env.EnvironmentConfig
-		// details coming from suites when targeting a real environment.
+		HTTP:    httpclient.New(resolver),
+		Logger:  utils.NewLogger(),
+		WorkDir: workDir,
 	}
 
-	run := runner.New(executor.BuildExecutor(), declExec)
-	if err := run.RunAll(ctx, suites); err != nil {
+	run := runner.New(executor.BuildRegistry(workDir), declExec)
+	run.Databases = databases
+	switch *provision {
+	case "":
+	case "existing":
+		run.Provisioner = existing
+	case "docker":
+		run.Provisioner = &env.Docker{}
+	default:
+		log.Fatalf("unknown -provision value %q", *provision)
+	}
+	run.ContinueOnFailure = *continueOnFailure
+	run.Concurrency = *concurrency
+	run.SuiteConcurrency = *suiteConcurrency
+	results, err := run.RunAll(ctx, suites)
+	for _, res := range results {
+		counts := res.Counts()
+		log.Printf("suite %s: %s (pass=%d fail=%d error=%d skip=%d) in %s", res.ID, res.Status,
+			counts[result.StatusPass], counts[result.StatusFail], counts[result.StatusError], counts[result.StatusSkip], res.Duration)
+	}
+	if *junitPath != "" {
+		if reportErr := report.WriteJUnitFile(*junitPath, results); reportErr != nil {
+			log.Printf("writing junit report failed: %v", reportErr)
+		}
+	}
+	if *htmlPath != "" {
+		if reportErr := report.WriteHTMLFile(*htmlPath, results); reportErr != nil {
+			log.Printf("writing html report failed: %v", reportErr)
+		}
+	}
+	if err != nil {
 		log.Fatalf("suite execution failed: %v", err)
 	}
+	if !result.Passed(results) {
+		log.Fatal("suite execution failed: not every suite passed")
+	}
 }
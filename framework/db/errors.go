@@ -0,0 +1,11 @@
+package db
+
+// QueryError is an error raised by a driver while running a query, such as a
+// syntax error or a lost connection, as opposed to a result that did not
+// match. Retrying the query does not fix it.
+type QueryError struct {
+	Err error
+}
+
+func (e *QueryError) Error() string { return e.Err.Error() }
+func (e *QueryError) Unwrap() error { return e.Err }
@@ -2,11 +2,16 @@ package mongo
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
+	"go.mongodb.org/mongo-driver/bson"
 	mongodriver "go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/example/go-test-framework/framework/db"
+	"github.com/example/go-test-framework/framework/match"
 )
 
 // Client executes queries and validations against MongoDB.
@@ -45,11 +50,11 @@ func (c *Client) Collection(database, collection string) (*mongodriver.Collectio
 func (c *Client) ValidateCount(ctx context.Context, database, collection string, query any, expected int64) error {
 	col, err := c.Collection(database, collection)
 	if err != nil {
-		return err
+		return &db.QueryError{Err: err}
 	}
 	count, err := col.CountDocuments(ctx, query)
 	if err != nil {
-		return err
+		return &db.QueryError{Err: err}
 	}
 	if count != expected {
 		return fmt.Errorf("expected %d documents, got %d", expected, count)
@@ -57,19 +62,103 @@ func (c *Client) ValidateCount(ctx context.Context, database, collection string,
 	return nil
 }
 
-func (c *Client) ValidateContains(ctx context.Context, database, collection string, query any, contains map[string]any) error {
+// Find returns the documents of a collection matching query.
+func (c *Client) Find(ctx context.Context, database, collection string, query any) ([]map[string]any, error) {
 	col, err := c.Collection(database, collection)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	var result map[string]any
-	if err := col.FindOne(ctx, query).Decode(&result); err != nil {
-		return err
+	cur, err := col.Find(ctx, query)
+	if err != nil {
+		return nil, err
 	}
-	for key, expected := range contains {
-		if val, ok := result[key]; !ok || fmt.Sprint(val) != fmt.Sprint(expected) {
-			return fmt.Errorf("field %s expected %v got %v", key, expected, val)
-		}
+	return decodeAll(ctx, cur)
+}
+
+// ValidateRows checks the documents matching query against want.
+func (c *Client) ValidateRows(ctx context.Context, database, collection string, query any, want match.RowSpec) error {
+	docs, err := c.Find(ctx, database, collection, query)
+	if err != nil {
+		return &db.QueryError{Err: err}
+	}
+	return want.Check(docs)
+}
+
+// Aggregate runs pipeline on a collection and returns the resulting documents.
+func (c *Client) Aggregate(ctx context.Context, database, collection string, pipeline []bson.D) ([]map[string]any, error) {
+	col, err := c.Collection(database, collection)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAll(ctx, cur)
+}
+
+// ValidatePipelineCount checks the number of documents produced by pipeline.
+func (c *Client) ValidatePipelineCount(ctx context.Context, database, collection string, pipeline []bson.D, expected int) error {
+	docs, err := c.Aggregate(ctx, database, collection, pipeline)
+	if err != nil {
+		return &db.QueryError{Err: err}
+	}
+	if len(docs) != expected {
+		return fmt.Errorf("expected %d documents from pipeline, got %d", expected, len(docs))
 	}
 	return nil
 }
+
+// ValidatePipelineRows checks the documents produced by pipeline against
+// want.
+func (c *Client) ValidatePipelineRows(ctx context.Context, database, collection string, pipeline []bson.D, want match.RowSpec) error {
+	docs, err := c.Aggregate(ctx, database, collection, pipeline)
+	if err != nil {
+		return &db.QueryError{Err: err}
+	}
+	return want.Check(docs)
+}
+
+// decodeAll reads the remaining documents of cur, normalized by db.Normalize.
+func decodeAll(ctx context.Context, cur *mongodriver.Cursor) ([]map[string]any, error) {
+	var docs []map[string]any
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	for _, doc := range docs {
+		db.NormalizeRow(doc)
+	}
+	return docs, nil
+}
+
+// Filter converts a query into BSON, turning extended JSON values such as
+// {"$oid": "..."}, {"$date": "..."} or {"$numberDecimal": "10.50"} into their
+// BSON types. Typed Go values and query operators pass through unchanged.
+func Filter(query map[string]any) (bson.D, error) {
+	if len(query) == 0 {
+		return bson.D{}, nil
+	}
+	data, err := bson.MarshalExtJSON(query, true, false)
+	if err != nil {
+		return nil, err
+	}
+	var filter bson.D
+	if err := bson.UnmarshalExtJSON(data, false, &filter); err != nil {
+		return nil, fmt.Errorf("invalid query %s: %w", data, err)
+	}
+	return filter, nil
+}
+
+// Pipeline decodes the extended JSON stages of an aggregation pipeline,
+// keeping their key order so stages such as $sort apply as written.
+func Pipeline(stages []json.RawMessage) ([]bson.D, error) {
+	out := make([]bson.D, len(stages))
+	for i, stage := range stages {
+		var d bson.D
+		if err := bson.UnmarshalExtJSON(stage, false, &d); err != nil {
+			return nil, fmt.Errorf("stage %d: invalid stage %s: %w", i+1, stage, err)
+		}
+		out[i] = d
+	}
+	return out, nil
+}
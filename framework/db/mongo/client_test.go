@@ -0,0 +1,137 @@
+package mongo
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/example/go-test-framework/framework/utils"
+)
+
+const bonusHex = "65a1b2c3d4e5f60718293a4b"
+
+func TestFilter(t *testing.T) {
+	oid, _ := primitive.ObjectIDFromHex(bonusHex)
+	newYear := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	decimal, _ := primitive.ParseDecimal128("10.50")
+	vars := map[string]string{"bonusId": bonusHex}
+
+	tests := []struct {
+		name  string
+		query map[string]any
+		want  map[string]any
+	}{
+		{name: "empty", query: nil, want: map[string]any{}},
+		{
+			name:  "object id",
+			query: map[string]any{"_id": map[string]any{"$oid": bonusHex}},
+			want:  map[string]any{"_id": oid},
+		},
+		{
+			name:  "substituted object id",
+			query: map[string]any{"_id": map[string]any{"$oid": "${bonusId}"}},
+			want:  map[string]any{"_id": oid},
+		},
+		{
+			name:  "date",
+			query: map[string]any{"createdAt": map[string]any{"$date": "2024-01-01T00:00:00Z"}},
+			want:  map[string]any{"createdAt": primitive.NewDateTimeFromTime(newYear)},
+		},
+		{
+			name:  "decimal",
+			query: map[string]any{"amount": map[string]any{"$numberDecimal": "10.50"}},
+			want:  map[string]any{"amount": decimal},
+		},
+		{
+			name:  "operators with extended JSON values",
+			query: map[string]any{"createdAt": map[string]any{"$gte": map[string]any{"$date": "2024-01-01T00:00:00Z"}}, "status": map[string]any{"$in": []any{"active", "pending"}}},
+			want: map[string]any{
+				"createdAt": bson.D{{Key: "$gte", Value: primitive.NewDateTimeFromTime(newYear)}},
+				"status":    bson.D{{Key: "$in", Value: bson.A{"active", "pending"}}},
+			},
+		},
+		{
+			name:  "typed Go values",
+			query: map[string]any{"_id": oid, "createdAt": newYear, "userId": 1, "amount": float64(100), "active": true},
+			want:  map[string]any{"_id": oid, "createdAt": primitive.NewDateTimeFromTime(newYear), "userId": int32(1), "amount": float64(100), "active": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := utils.Substitute(tt.query, vars).(map[string]any)
+			filter, err := Filter(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]any, len(filter))
+			for _, e := range filter {
+				got[e.Key] = e.Value
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Filter = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterInvalidObjectID(t *testing.T) {
+	_, err := Filter(map[string]any{"_id": map[string]any{"$oid": "not-a-hex-id"}})
+	if err == nil || !strings.Contains(err.Error(), "not a valid ObjectID") {
+		t.Fatalf("error %v", err)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	oid, _ := primitive.ObjectIDFromHex(bonusHex)
+	vars := map[string]string{"bonusId": bonusHex}
+	stages := []json.RawMessage{
+		json.RawMessage(`{"$match": {"_id": {"$oid": "${bonusId}"}, "amount": {"$gte": {"$numberDecimal": "10"}}}}`),
+		json.RawMessage(`{"$sort": {"b": 1, "a": -1, "c": 1}}`),
+		json.RawMessage(`{"$lookup": {"from": "wallets", "localField": "_id", "foreignField": "bonusId", "as": "wallets"}}`),
+	}
+	for i, stage := range stages {
+		stages[i] = utils.SubstituteJSON(stage, vars)
+	}
+	pipeline, err := Pipeline(stages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ten, _ := primitive.ParseDecimal128("10")
+	want := []bson.D{
+		{{Key: "$match", Value: bson.D{{Key: "_id", Value: oid}, {Key: "amount", Value: bson.D{{Key: "$gte", Value: ten}}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "b", Value: int32(1)}, {Key: "a", Value: int32(-1)}, {Key: "c", Value: int32(1)}}}},
+		{{Key: "$lookup", Value: bson.D{{Key: "from", Value: "wallets"}, {Key: "localField", Value: "_id"}, {Key: "foreignField", Value: "bonusId"}, {Key: "as", Value: "wallets"}}}},
+	}
+	if !reflect.DeepEqual(pipeline, want) {
+		t.Fatalf("Pipeline =\n%#v\nwant\n%#v", pipeline, want)
+	}
+}
+
+func TestPipelineErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		stages  []string
+		wantErr string
+	}{
+		{name: "invalid object id", stages: []string{`{"$match": {}}`, `{"$match": {"_id": {"$oid": "zz"}}}`}, wantErr: "stage 2: invalid stage"},
+		{name: "invalid object id message", stages: []string{`{"$match": {"_id": {"$oid": "zz"}}}`}, wantErr: "not a valid ObjectID"},
+		{name: "malformed JSON", stages: []string{`{"$match":`}, wantErr: "stage 1: invalid stage"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stages := make([]json.RawMessage, len(tt.stages))
+			for i, s := range tt.stages {
+				stages[i] = json.RawMessage(s)
+			}
+			_, err := Pipeline(stages)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// Package db converts values returned by the database drivers into the plain
+// Go values the matchers understand.
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Normalize returns the canonical form of a driver value:
+//
+//   - decimals (numeric, Decimal128) become json.Number, so 100 and 100.00
+//     compare equal without float rounding; NaN and infinities become strings
+//   - UUIDs become their lower-case string form and ObjectIDs their hex
+//   - timestamps and dates become time.Time in UTC
+//   - documents and JSON become map[string]any and []any, recursively
+//
+// Values without a better representation are returned unchanged.
+func Normalize(v any) any {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		return normalizeMap(val)
+	case []any:
+		return normalizeSlice(val)
+	case time.Time:
+		return val.UTC()
+	case [16]byte:
+		return formatUUID(val)
+	case pgtype.UUID:
+		if !val.Valid {
+			return nil
+		}
+		return formatUUID(val.Bytes)
+	case pgtype.Numeric:
+		return normalizeNumeric(val)
+	case primitive.ObjectID:
+		return val.Hex()
+	case primitive.Decimal128:
+		if val.IsNaN() || val.IsInf() != 0 {
+			return val.String()
+		}
+		return json.Number(val.String())
+	case primitive.DateTime:
+		return val.Time().UTC()
+	case primitive.Timestamp:
+		return time.Unix(int64(val.T), 0).UTC()
+	case primitive.D:
+		out := make(map[string]any, len(val))
+		for _, e := range val {
+			out[e.Key] = Normalize(e.Value)
+		}
+		return out
+	case primitive.M:
+		return normalizeMap(val)
+	case primitive.A:
+		return normalizeSlice(val)
+	case primitive.Binary:
+		if (val.Subtype == 0x04 || val.Subtype == 0x03) && len(val.Data) == 16 {
+			return formatUUID([16]byte(val.Data))
+		}
+		return val.Data
+	case primitive.Null, primitive.Undefined:
+		return nil
+	case primitive.Regex:
+		return val.String()
+	case driver.Valuer:
+		dv, err := val.Value()
+		if err != nil {
+			return v
+		}
+		return Normalize(dv)
+	}
+	return v
+}
+
+// NormalizeRow normalizes every column of row in place and returns it.
+func NormalizeRow(row map[string]any) map[string]any {
+	for k, v := range row {
+		row[k] = Normalize(v)
+	}
+	return row
+}
+
+func normalizeMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = Normalize(v)
+	}
+	return out
+}
+
+func normalizeSlice(s []any) []any {
+	out := make([]any, len(s))
+	for i, v := range s {
+		out[i] = Normalize(v)
+	}
+	return out
+}
+
+func normalizeNumeric(n pgtype.Numeric) any {
+	switch {
+	case !n.Valid:
+		return nil
+	case n.NaN:
+		return "NaN"
+	case n.InfinityModifier == pgtype.Infinity:
+		return "Infinity"
+	case n.InfinityModifier == pgtype.NegativeInfinity:
+		return "-Infinity"
+	}
+	text, err := n.MarshalJSON()
+	if err != nil {
+		return n
+	}
+	return json.Number(text)
+}
+
+func formatUUID(b [16]byte) string {
+	s := hex.EncodeToString(b[:])
+	return fmt.Sprintf("%s-%s-%s-%s-%s", s[0:8], s[8:12], s[12:16], s[16:20], s[20:])
+}
@@ -0,0 +1,116 @@
+package db
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/example/go-test-framework/framework/match"
+)
+
+func decimal128(t *testing.T, s string) primitive.Decimal128 {
+	t.Helper()
+	d, err := primitive.ParseDecimal128(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestNormalize(t *testing.T) {
+	uuid := [16]byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+	const uuidText = "123e4567-e89b-12d3-a456-426614174000"
+	oid, _ := primitive.ObjectIDFromHex("65a1b2c3d4e5f60718293a4b")
+	kyiv := time.FixedZone("EET", 2*60*60)
+	local := time.Date(2024, 1, 1, 2, 30, 0, 0, kyiv)
+	utc := time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		in   any
+		want any
+	}{
+		{name: "nil", in: nil, want: nil},
+		{name: "plain value", in: "active", want: "active"},
+		{name: "numeric", in: pgtype.Numeric{Int: big.NewInt(10000), Exp: -2, Valid: true}, want: json.Number("100.00")},
+		{name: "numeric negative exponent", in: pgtype.Numeric{Int: big.NewInt(-5), Exp: -3, Valid: true}, want: json.Number("-0.005")},
+		{name: "numeric positive exponent", in: pgtype.Numeric{Int: big.NewInt(12), Exp: 2, Valid: true}, want: json.Number("1200")},
+		{name: "numeric null", in: pgtype.Numeric{}, want: nil},
+		{name: "numeric NaN", in: pgtype.Numeric{NaN: true, Valid: true}, want: "NaN"},
+		{name: "numeric infinity", in: pgtype.Numeric{InfinityModifier: pgtype.Infinity, Valid: true}, want: "Infinity"},
+		{name: "numeric negative infinity", in: pgtype.Numeric{InfinityModifier: pgtype.NegativeInfinity, Valid: true}, want: "-Infinity"},
+		{name: "uuid bytes", in: uuid, want: uuidText},
+		{name: "pgtype uuid", in: pgtype.UUID{Bytes: uuid, Valid: true}, want: uuidText},
+		{name: "pgtype uuid null", in: pgtype.UUID{}, want: nil},
+		{name: "non-UTC time", in: local, want: utc},
+		{name: "decimal128", in: decimal128(t, "10.50"), want: json.Number("10.50")},
+		{name: "decimal128 NaN", in: decimal128(t, "NaN"), want: "NaN"},
+		{name: "decimal128 infinity", in: decimal128(t, "-Infinity"), want: "-Infinity"},
+		{name: "object id", in: oid, want: "65a1b2c3d4e5f60718293a4b"},
+		{name: "mongo date", in: primitive.NewDateTimeFromTime(utc), want: utc},
+		{name: "mongo timestamp", in: primitive.Timestamp{T: uint32(utc.Unix())}, want: utc},
+		{name: "binary uuid subtype 4", in: primitive.Binary{Subtype: 0x04, Data: uuid[:]}, want: uuidText},
+		{name: "binary legacy uuid subtype 3", in: primitive.Binary{Subtype: 0x03, Data: uuid[:]}, want: uuidText},
+		{name: "binary data", in: primitive.Binary{Subtype: 0x00, Data: []byte("raw")}, want: []byte("raw")},
+		{name: "binary subtype 4 of the wrong length", in: primitive.Binary{Subtype: 0x04, Data: []byte{1, 2}}, want: []byte{1, 2}},
+		{name: "mongo null", in: primitive.Null{}, want: nil},
+		{name: "regex", in: primitive.Regex{Pattern: "^b-", Options: "i"}, want: "{\"pattern\": \"^b-\", \"options\": \"i\"}"},
+		{
+			name: "document",
+			in: primitive.D{
+				{Key: "_id", Value: oid},
+				{Key: "amount", Value: decimal128(t, "100")},
+				{Key: "tags", Value: primitive.A{"new", primitive.D{{Key: "at", Value: primitive.NewDateTimeFromTime(utc)}}}},
+			},
+			want: map[string]any{
+				"_id":    "65a1b2c3d4e5f60718293a4b",
+				"amount": json.Number("100"),
+				"tags":   []any{"new", map[string]any{"at": utc}},
+			},
+		},
+		{
+			name: "nested maps and lists",
+			in:   map[string]any{"wallet": map[string]any{"id": uuid, "balance": pgtype.Numeric{Int: big.NewInt(5), Valid: true}}, "at": []any{local}},
+			want: map[string]any{"wallet": map[string]any{"id": uuidText, "balance": json.Number("5")}, "at": []any{utc}},
+		},
+		{name: "driver valuer", in: pgtype.Text{String: "promo", Valid: true}, want: "promo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Normalize(tt.in)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Normalize(%#v) = %#v, want %#v", tt.in, got, tt.want)
+			}
+			if want, ok := tt.want.(time.Time); ok && got.(time.Time).Location() != time.UTC {
+				t.Fatalf("time %s is not in UTC (want %s)", got, want)
+			}
+		})
+	}
+}
+
+func TestNormalizedDecimalsCompareExactly(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected any
+	}{
+		{name: "numeric 100.00 equals 100", value: pgtype.Numeric{Int: big.NewInt(10000), Exp: -2, Valid: true}, expected: 100},
+		{name: "numeric equals a decimal string", value: pgtype.Numeric{Int: big.NewInt(1050), Exp: -2, Valid: true}, expected: "10.5"},
+		{name: "decimal128 10.50 equals 10.5", value: decimal128(t, "10.50"), expected: 10.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := match.Value(Normalize(tt.value), tt.expected); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+	if err := match.Value(Normalize(pgtype.Numeric{Int: big.NewInt(10001), Exp: -2, Valid: true}), 100); err == nil {
+		t.Fatal("100.01 matched 100")
+	}
+}
@@ -0,0 +1,286 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/example/go-test-framework/framework/db/mongo"
+	"github.com/example/go-test-framework/framework/db/postgres"
+	"github.com/example/go-test-framework/framework/db/redis"
+	"github.com/example/go-test-framework/framework/env"
+	"github.com/example/go-test-framework/framework/mq/kafka"
+	"github.com/example/go-test-framework/framework/mq/rabbitmq"
+	"github.com/example/go-test-framework/framework/utils"
+)
+
+// Templates are connection strings with ${database}, ${username} and
+// ${password} placeholders filled from the suite's declared database
+// entries, e.g. "postgres://${username}:${password}@db:5432/${database}".
+// Credentials are URL-escaped before substitution.
+type Templates struct {
+	Postgres string
+	Mongo    string
+	// Redis is an address or redis:// URL, RabbitMQ an amqp:// URL and
+	// Kafka a comma-separated broker list; none has placeholders.
+	Redis    string
+	RabbitMQ string
+	Kafka    string
+}
+
+// Pool hands out the database clients of one suite. Clients are created on
+// first use, shared by every test of the suite and closed by Close.
+//
+// A database's URL comes from the provisioned Endpoints when present,
+// otherwise from Templates and the matching Environment entry. The empty
+// database name selects the first declared database.
+type Pool struct {
+	Templates   Templates
+	Environment env.EnvironmentConfig
+	Endpoints   *env.Endpoints
+
+	mu       sync.Mutex
+	postgres map[string]*postgres.Client
+	mongo    map[string]*mongo.Client
+	redis    *redis.Client
+	rabbitmq *rabbitmq.Client
+	kafka    *kafka.Client
+}
+
+// New returns a pool for a suite environment; endpoints may be nil.
+func New(templates Templates, cfg env.EnvironmentConfig, endpoints *env.Endpoints) *Pool {
+	return &Pool{Templates: templates, Environment: cfg, Endpoints: endpoints}
+}
+
+// HasPostgres reports whether name is a declared Postgres database.
+func (p *Pool) HasPostgres(name string) bool {
+	if p == nil || p.Environment.Postgres == nil || name == "" {
+		return false
+	}
+	for _, db := range p.Environment.Postgres.Databases {
+		if db.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HasMongo reports whether name is a declared MongoDB database.
+func (p *Pool) HasMongo(name string) bool {
+	if p == nil || p.Environment.Mongo == nil || name == "" {
+		return false
+	}
+	for _, db := range p.Environment.Mongo.Databases {
+		if db.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Postgres returns the client for database name.
+func (p *Pool) Postgres(ctx context.Context, name string) (*postgres.Client, error) {
+	if p == nil {
+		return nil, errors.New("postgres client is not configured")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	dsn, err := p.postgresURL(name)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := p.postgres[dsn]; ok {
+		return c, nil
+	}
+	c, err := postgres.New(ctx, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("connect postgres %s: %w", displayName(name), err)
+	}
+	if p.postgres == nil {
+		p.postgres = map[string]*postgres.Client{}
+	}
+	p.postgres[dsn] = c
+	return c, nil
+}
+
+// Mongo returns the client authenticated for database name.
+func (p *Pool) Mongo(ctx context.Context, name string) (*mongo.Client, error) {
+	if p == nil {
+		return nil, errors.New("mongo client is not configured")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	uri, err := p.mongoURL(name)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := p.mongo[uri]; ok {
+		return c, nil
+	}
+	c, err := mongo.New(ctx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("connect mongodb %s: %w", displayName(name), err)
+	}
+	if p.mongo == nil {
+		p.mongo = map[string]*mongo.Client{}
+	}
+	p.mongo[uri] = c
+	return c, nil
+}
+
+// Redis returns the suite's Redis client.
+func (p *Pool) Redis(ctx context.Context) (*redis.Client, error) {
+	if p == nil {
+		return nil, errors.New("redis client is not configured")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.redis != nil {
+		return p.redis, nil
+	}
+	addr := p.Templates.Redis
+	if p.Endpoints != nil && p.Endpoints.Redis != "" {
+		addr = p.Endpoints.Redis
+	}
+	if addr == "" {
+		return nil, errors.New("no redis connection configured")
+	}
+	c, err := redis.New(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("connect redis: %w", err)
+	}
+	p.redis = c
+	return c, nil
+}
+
+// RabbitMQ returns the suite's RabbitMQ client.
+func (p *Pool) RabbitMQ(ctx context.Context) (*rabbitmq.Client, error) {
+	if p == nil {
+		return nil, errors.New("rabbitmq client is not configured")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.rabbitmq != nil {
+		return p.rabbitmq, nil
+	}
+	url := p.Templates.RabbitMQ
+	if p.Endpoints != nil && p.Endpoints.RabbitMQ != "" {
+		url = p.Endpoints.RabbitMQ
+	}
+	if url == "" {
+		return nil, errors.New("no rabbitmq connection configured")
+	}
+	c, err := rabbitmq.New(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("connect rabbitmq: %w", err)
+	}
+	p.rabbitmq = c
+	return c, nil
+}
+
+// Kafka returns the suite's Kafka client.
+func (p *Pool) Kafka(ctx context.Context) (*kafka.Client, error) {
+	if p == nil {
+		return nil, errors.New("kafka client is not configured")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.kafka != nil {
+		return p.kafka, nil
+	}
+	if p.Templates.Kafka == "" {
+		return nil, errors.New("no kafka brokers configured")
+	}
+	c, err := kafka.New(ctx, p.Templates.Kafka)
+	if err != nil {
+		return nil, fmt.Errorf("connect kafka: %w", err)
+	}
+	p.kafka = c
+	return c, nil
+}
+
+// Close disconnects every client handed out so far.
+func (p *Pool) Close(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var errs []error
+	for _, c := range p.postgres {
+		c.Close()
+	}
+	for _, c := range p.mongo {
+		errs = append(errs, c.Close(ctx))
+	}
+	errs = append(errs, p.redis.Close(), p.rabbitmq.Close(), p.kafka.Close())
+	p.postgres, p.mongo, p.redis, p.rabbitmq, p.kafka = nil, nil, nil, nil, nil
+	return errors.Join(errs...)
+}
+
+func (p *Pool) postgresURL(name string) (string, error) {
+	if p.Endpoints != nil {
+		if dsn, ok := p.Endpoints.PostgresURL(name); ok {
+			return dsn, nil
+		}
+	}
+	var entries []credentials
+	if pg := p.Environment.Postgres; pg != nil {
+		for _, db := range pg.Databases {
+			entries = append(entries, credentials{db.Name, db.Username, db.Password})
+		}
+	}
+	return fromTemplate("postgres", p.Templates.Postgres, entries, name)
+}
+
+func (p *Pool) mongoURL(name string) (string, error) {
+	if p.Endpoints != nil {
+		if uri, ok := p.Endpoints.MongoURL(name); ok {
+			return uri, nil
+		}
+	}
+	var entries []credentials
+	if mg := p.Environment.Mongo; mg != nil {
+		for _, db := range mg.Databases {
+			entries = append(entries, credentials{db.Name, db.Username, db.Password})
+		}
+	}
+	return fromTemplate("mongodb", p.Templates.Mongo, entries, name)
+}
+
+type credentials struct {
+	database, username, password string
+}
+
+func fromTemplate(kind, template string, entries []credentials, name string) (string, error) {
+	if template == "" {
+		return "", fmt.Errorf("no %s connection configured for database %s", kind, displayName(name))
+	}
+	entry := credentials{database: name}
+	found := false
+	for _, e := range entries {
+		if name == "" || e.database == name {
+			entry, found = e, true
+			break
+		}
+	}
+	if !found && (strings.Contains(template, "${username}") || strings.Contains(template, "${password}") || (name == "" && strings.Contains(template, "${database}"))) {
+		return "", fmt.Errorf("%s database %s is not declared in the suite environment", kind, displayName(name))
+	}
+	vars := map[string]string{
+		"database": url.PathEscape(entry.database),
+		"username": url.User(entry.username).String(),
+		"password": strings.TrimPrefix(url.UserPassword("", entry.password).String(), ":"),
+	}
+	return utils.Substitute(template, vars).(string), nil
+}
+
+func displayName(name string) string {
+	if name == "" {
+		return "(default)"
+	}
+	return name
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/example/go-test-framework/framework/db"
+	"github.com/example/go-test-framework/framework/match"
 )
 
 // Client is a lightweight wrapper around pgx connection pool with helpers used
@@ -33,11 +35,12 @@ func (c *Client) Close() {
 	c.pool.Close()
 }
 
-func (c *Client) Query(ctx context.Context, schema, table string, filters map[string]any) ([]map[string]any, error) {
+// Query runs a statement built by BuildSelect or BindSQL and returns its rows
+// keyed by column name, with values normalized by db.Normalize.
+func (c *Client) Query(ctx context.Context, qb QueryBuilder) ([]map[string]any, error) {
 	if c.pool == nil {
 		return nil, errors.New("postgres client not configured")
 	}
-	qb := BuildSelect(schema, table, filters)
 	rows, err := c.pool.Query(ctx, qb.SQL, qb.Args...)
 	if err != nil {
 		return nil, err
@@ -53,17 +56,17 @@ func (c *Client) Query(ctx context.Context, schema, table string, filters map[st
 		}
 		row := map[string]any{}
 		for i, col := range cols {
-			row[string(col.Name)] = values[i]
+			row[string(col.Name)] = db.Normalize(values[i])
 		}
 		result = append(result, row)
 	}
 	return result, rows.Err()
 }
 
-func (c *Client) ValidateCount(ctx context.Context, schema, table string, filters map[string]any, expected int) error {
-	records, err := c.Query(ctx, schema, table, filters)
+func (c *Client) ValidateCount(ctx context.Context, qb QueryBuilder, expected int) error {
+	records, err := c.Query(ctx, qb)
 	if err != nil {
-		return err
+		return &db.QueryError{Err: err}
 	}
 	if len(records) != expected {
 		return fmt.Errorf("expected %d rows, got %d", expected, len(records))
@@ -71,53 +74,11 @@ func (c *Client) ValidateCount(ctx context.Context, schema, table string, filter
 	return nil
 }
 
-func (c *Client) ValidateContains(ctx context.Context, schema, table string, filters map[string]any, expected map[string]any) error {
-	records, err := c.Query(ctx, schema, table, filters)
+// ValidateRows checks the rows returned by qb against want.
+func (c *Client) ValidateRows(ctx context.Context, qb QueryBuilder, want match.RowSpec) error {
+	records, err := c.Query(ctx, qb)
 	if err != nil {
-		return err
-	}
-	if len(records) == 0 {
-		return errors.New("no rows returned for contains validation")
-	}
-	for key, expectedVal := range expected {
-		found := false
-		for _, row := range records {
-			if v, ok := row[key]; ok && fmt.Sprint(v) == fmt.Sprint(expectedVal) {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return fmt.Errorf("expected field %s=%v not found", key, expectedVal)
-		}
-	}
-	return nil
-}
-
-// QueryBuilder is a trivial SELECT query generator.
-type QueryBuilder struct {
-	SQL  string
-	Args []any
-}
-
-func BuildSelect(schema, table string, filters map[string]any) QueryBuilder {
-	fullTable := table
-	if schema != "" {
-		fullTable = fmt.Sprintf("%s.%s", schema, table)
-	}
-	qb := QueryBuilder{SQL: fmt.Sprintf("SELECT * FROM %s", fullTable)}
-	if len(filters) == 0 {
-		return qb
-	}
-	clauses := make([]string, 0, len(filters))
-	args := make([]any, 0, len(filters))
-	idx := 1
-	for k, v := range filters {
-		clauses = append(clauses, fmt.Sprintf("%s = $%d", k, idx))
-		args = append(args, v)
-		idx++
+		return &db.QueryError{Err: err}
 	}
-	qb.SQL = fmt.Sprintf("%s WHERE %s", qb.SQL, strings.Join(clauses, " AND "))
-	qb.Args = args
-	return qb
+	return want.Check(records)
 }
@@ -0,0 +1,399 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Select describes the rows an assertion targets.
+//
+// Filters map columns to values. A plain value compares with =, nil with IS
+// NULL, and an object of operators such as {"$gte": 10, "$lt": 20} applies
+// each of them: $eq, $ne, $gt, $gte, $lt, $lte, $in, $nin, $like, $ilike and
+// $null (true for IS NULL, false for IS NOT NULL). A column may address a
+// JSONB field with -> and ->>, e.g. "payload->>currency" or "items->0->>id";
+// integer segments index arrays and ->> yields text.
+//
+// OrderBy entries are a column optionally followed by asc or desc, e.g.
+// "created_at desc"; a positive Limit caps the number of rows.
+type Select struct {
+	Schema  string
+	Table   string
+	Filters map[string]any
+	OrderBy []string
+	Limit   int
+}
+
+// QueryBuilder is a SELECT statement with its positional arguments.
+type QueryBuilder struct {
+	SQL  string
+	Args []any
+}
+
+// BuildSelect renders sel with quoted identifiers. Filters are rendered in
+// column order so the same selection always yields the same SQL and args.
+func BuildSelect(sel Select) (QueryBuilder, error) {
+	if sel.Table == "" {
+		return QueryBuilder{}, errors.New("table is required")
+	}
+	table := pgx.Identifier{sel.Table}
+	if sel.Schema != "" {
+		table = pgx.Identifier{sel.Schema, sel.Table}
+	}
+	b := &builder{}
+	b.sql.WriteString("SELECT * FROM ")
+	b.sql.WriteString(table.Sanitize())
+
+	columns := make([]string, 0, len(sel.Filters))
+	for column := range sel.Filters {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	var clauses []string
+	for _, column := range columns {
+		c, err := b.filter(column, sel.Filters[column])
+		if err != nil {
+			return QueryBuilder{}, err
+		}
+		clauses = append(clauses, c...)
+	}
+	if len(clauses) > 0 {
+		b.sql.WriteString(" WHERE ")
+		b.sql.WriteString(strings.Join(clauses, " AND "))
+	}
+
+	if len(sel.OrderBy) > 0 {
+		terms := make([]string, 0, len(sel.OrderBy))
+		for _, entry := range sel.OrderBy {
+			term, err := b.orderTerm(entry)
+			if err != nil {
+				return QueryBuilder{}, err
+			}
+			terms = append(terms, term)
+		}
+		b.sql.WriteString(" ORDER BY ")
+		b.sql.WriteString(strings.Join(terms, ", "))
+	}
+	if sel.Limit > 0 {
+		fmt.Fprintf(&b.sql, " LIMIT %d", sel.Limit)
+	}
+	return QueryBuilder{SQL: b.sql.String(), Args: b.args}, nil
+}
+
+var variablePattern = regexp.MustCompile(`\$\{([a-zA-Z0-9_\-]+)\}`)
+
+// BindSQL turns a raw statement into a parameterized one: every ${var} is
+// replaced by a positional parameter bound to the variable's value, so values
+// never become part of the SQL text. Repeated variables share a parameter.
+// Placeholders must not be quoted; write `user_id = ${userId}`, not
+// `user_id = '${userId}'`, which is rejected, as are placeholders in E'...'
+// or dollar-quoted strings, quoted identifiers and comments.
+func BindSQL(sql string, vars map[string]string) (QueryBuilder, error) {
+	if strings.TrimSpace(sql) == "" {
+		return QueryBuilder{}, errors.New("sql is empty")
+	}
+	regions := quotedRegions(sql)
+	qb := QueryBuilder{}
+	index := map[string]int{}
+	var (
+		out     strings.Builder
+		last    int
+		missing []string
+	)
+	for _, m := range variablePattern.FindAllStringSubmatchIndex(sql, -1) {
+		ph, name := sql[m[0]:m[1]], sql[m[2]:m[3]]
+		if r, ok := regionAt(regions, m[0]); ok {
+			if r.kind == "comment" {
+				return QueryBuilder{}, fmt.Errorf("placeholder %s is inside a comment", ph)
+			}
+			return QueryBuilder{}, fmt.Errorf("placeholder %s is inside a %s; remove the quotes around it", ph, r.kind)
+		}
+		out.WriteString(sql[last:m[0]])
+		last = m[1]
+		n, ok := index[name]
+		if !ok {
+			val, defined := vars[name]
+			if !defined {
+				if !slices.Contains(missing, name) {
+					missing = append(missing, name)
+				}
+				out.WriteString(ph)
+				continue
+			}
+			qb.Args = append(qb.Args, val)
+			n = len(qb.Args)
+			index[name] = n
+		}
+		fmt.Fprintf(&out, "$%d", n)
+	}
+	out.WriteString(sql[last:])
+	if len(missing) > 0 {
+		return QueryBuilder{}, fmt.Errorf("sql uses undefined variables %s", strings.Join(missing, ", "))
+	}
+	qb.SQL = out.String()
+	return qb, nil
+}
+
+// quoted is a part of a statement that placeholders must not appear in.
+type quoted struct {
+	start, end int
+	kind       string
+}
+
+// quotedRegions returns the [start, end) byte ranges of the string literals,
+// escape strings (E'...'), dollar-quoted strings ($$...$$, $tag$...$tag$),
+// quoted identifiers and comments of sql. An unterminated region runs to the
+// end of the statement.
+func quotedRegions(sql string) []quoted {
+	var regions []quoted
+	for i := 0; i < len(sql); i++ {
+		start, kind := i, ""
+		switch c := sql[i]; {
+		case c == '\'':
+			escapes := i > 0 && (sql[i-1] == 'E' || sql[i-1] == 'e') && (i == 1 || !identChar(sql[i-2]))
+			if escapes {
+				start--
+			}
+			i, kind = closeQuote(sql, i, '\'', escapes), "string literal"
+		case c == '"':
+			i, kind = closeQuote(sql, i, '"', false), "quoted identifier"
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			i, kind = i+end-1, "comment"
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			i, kind = closeComment(sql, i), "comment"
+		case c == '$' && (i == 0 || !identChar(sql[i-1])):
+			tag := dollarTag.FindString(sql[i:])
+			if tag == "" {
+				continue
+			}
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end < 0 {
+				i = len(sql) - 1
+			} else {
+				i += len(tag) + end + len(tag) - 1
+			}
+			kind = "dollar-quoted string"
+		default:
+			continue
+		}
+		regions = append(regions, quoted{start: start, end: i + 1, kind: kind})
+	}
+	return regions
+}
+
+var dollarTag = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
+
+// closeQuote returns the index of the quote closing the one at open, or the
+// last index of sql. A doubled quote is an escape, and so is a backslash when
+// escapes is set.
+func closeQuote(sql string, open int, q byte, escapes bool) int {
+	for i := open + 1; i < len(sql); i++ {
+		switch {
+		case escapes && sql[i] == '\\', sql[i] == q && i+1 < len(sql) && sql[i+1] == q:
+			i++
+		case sql[i] == q:
+			return i
+		}
+	}
+	return len(sql) - 1
+}
+
+// closeComment returns the index of the last byte of the block comment
+// opened at open; block comments nest.
+func closeComment(sql string, open int) int {
+	depth := 0
+	for i := open; i+1 < len(sql); i++ {
+		switch sql[i : i+2] {
+		case "/*":
+			depth++
+			i++
+		case "*/":
+			depth--
+			i++
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(sql) - 1
+}
+
+func identChar(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func regionAt(regions []quoted, pos int) (quoted, bool) {
+	for _, r := range regions {
+		if pos >= r.start && pos < r.end {
+			return r, true
+		}
+	}
+	return quoted{}, false
+}
+
+type builder struct {
+	sql  strings.Builder
+	args []any
+}
+
+func (b *builder) arg(v any) string {
+	b.args = append(b.args, v)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// column quotes a column reference. JSONB path segments after -> and ->> are
+// bound as arguments rather than spliced into the statement: integers as
+// array indexes, anything else as object keys.
+func (b *builder) column(ref string) (string, error) {
+	var out strings.Builder
+	rest := ref
+	first := true
+	for {
+		idx := strings.Index(rest, "->")
+		segment, op := rest, ""
+		if idx >= 0 {
+			segment, op = rest[:idx], "->"
+			if strings.HasPrefix(rest[idx:], "->>") {
+				op = "->>"
+			}
+		}
+		segment = strings.TrimSpace(segment)
+		if segment == "" {
+			return "", fmt.Errorf("invalid column %q", ref)
+		}
+		if first {
+			out.WriteString(pgx.Identifier{segment}.Sanitize())
+			first = false
+		} else {
+			if n, err := strconv.Atoi(segment); err == nil {
+				out.WriteString(b.arg(n) + "::int")
+			} else {
+				out.WriteString(b.arg(segment) + "::text")
+			}
+		}
+		if idx < 0 {
+			return out.String(), nil
+		}
+		out.WriteString(op)
+		rest = rest[idx+len(op):]
+	}
+}
+
+func (b *builder) filter(ref string, value any) ([]string, error) {
+	column, err := b.column(ref)
+	if err != nil {
+		return nil, err
+	}
+	ops, ok := operators(value)
+	if !ok {
+		if value == nil {
+			return []string{column + " IS NULL"}, nil
+		}
+		return []string{column + " = " + b.arg(value)}, nil
+	}
+
+	names := make([]string, 0, len(ops))
+	for name := range ops {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	clauses := make([]string, 0, len(names))
+	for _, name := range names {
+		operand := ops[name]
+		var clause string
+		switch name {
+		case "$eq", "$ne", "$gt", "$gte", "$lt", "$lte", "$like", "$ilike":
+			if operand == nil {
+				return nil, fmt.Errorf("column %s: %s needs a value, use $null to match NULL", ref, name)
+			}
+			clause = fmt.Sprintf("%s %s %s", column, comparisons[name], b.arg(operand))
+		case "$in", "$nin":
+			list, ok := operand.([]any)
+			if !ok {
+				return nil, fmt.Errorf("column %s: %s needs a list", ref, name)
+			}
+			clause = b.in(column, list, name == "$nin")
+		case "$null":
+			isNull, ok := operand.(bool)
+			if !ok {
+				return nil, fmt.Errorf("column %s: $null needs true or false", ref)
+			}
+			clause = column + " IS NULL"
+			if !isNull {
+				clause = column + " IS NOT NULL"
+			}
+		default:
+			return nil, fmt.Errorf("column %s: unsupported operator %s", ref, name)
+		}
+		clauses = append(clauses, clause)
+	}
+	return clauses, nil
+}
+
+func (b *builder) in(column string, list []any, negate bool) string {
+	if len(list) == 0 {
+		if negate {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	placeholders := make([]string, len(list))
+	for i, v := range list {
+		placeholders[i] = b.arg(v)
+	}
+	op := "IN"
+	if negate {
+		op = "NOT IN"
+	}
+	return fmt.Sprintf("%s %s (%s)", column, op, strings.Join(placeholders, ", "))
+}
+
+func (b *builder) orderTerm(entry string) (string, error) {
+	fields := strings.Fields(entry)
+	if len(fields) == 0 || len(fields) > 2 {
+		return "", fmt.Errorf("invalid orderBy %q", entry)
+	}
+	column, err := b.column(fields[0])
+	if err != nil {
+		return "", err
+	}
+	if len(fields) == 1 {
+		return column, nil
+	}
+	switch dir := strings.ToUpper(fields[1]); dir {
+	case "ASC", "DESC":
+		return column + " " + dir, nil
+	}
+	return "", fmt.Errorf("invalid orderBy direction %q", fields[1])
+}
+
+var comparisons = map[string]string{
+	"$eq": "=", "$ne": "<>", "$gt": ">", "$gte": ">=", "$lt": "<", "$lte": "<=",
+	"$like": "LIKE", "$ilike": "ILIKE",
+}
+
+// operators returns value as an operator object when every key starts with
+// "$"; other objects are compared as values.
+func operators(value any) (map[string]any, bool) {
+	m, ok := value.(map[string]any)
+	if !ok || len(m) == 0 {
+		return nil, false
+	}
+	for k := range m {
+		if !strings.HasPrefix(k, "$") {
+			return nil, false
+		}
+	}
+	return m, true
+}
@@ -0,0 +1,265 @@
+package postgres
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildSelect(t *testing.T) {
+	tests := []struct {
+		name     string
+		sel      Select
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:    "table only",
+			sel:     Select{Table: "bonus_wallets"},
+			wantSQL: `SELECT * FROM "bonus_wallets"`,
+		},
+		{
+			name:    "schema qualified",
+			sel:     Select{Schema: "admin", Table: "bonus_wallets"},
+			wantSQL: `SELECT * FROM "admin"."bonus_wallets"`,
+		},
+		{
+			name:     "identifiers with quotes and dots",
+			sel:      Select{Schema: `we"ird`, Table: "bonus.wallets", Filters: map[string]any{`user"id`: 1}},
+			wantSQL:  `SELECT * FROM "we""ird"."bonus.wallets" WHERE "user""id" = $1`,
+			wantArgs: []any{1},
+		},
+		{
+			name:     "mixed case and reserved words",
+			sel:      Select{Table: "Order", Filters: map[string]any{"userId": 1, "select": "x"}},
+			wantSQL:  `SELECT * FROM "Order" WHERE "select" = $1 AND "userId" = $2`,
+			wantArgs: []any{"x", 1},
+		},
+		{
+			name:     "injection attempt stays an identifier",
+			sel:      Select{Table: "t", Filters: map[string]any{"id = 1; DROP TABLE t; --": 1}},
+			wantSQL:  `SELECT * FROM "t" WHERE "id = 1; DROP TABLE t; --" = $1`,
+			wantArgs: []any{1},
+		},
+		{
+			name:     "filters in column order",
+			sel:      Select{Table: "t", Filters: map[string]any{"b": 2, "a": 1, "c": nil}},
+			wantSQL:  `SELECT * FROM "t" WHERE "a" = $1 AND "b" = $2 AND "c" IS NULL`,
+			wantArgs: []any{1, 2},
+		},
+		{
+			name:     "jsonb text field",
+			sel:      Select{Table: "t", Filters: map[string]any{"payload->>currency": "USD"}},
+			wantSQL:  `SELECT * FROM "t" WHERE "payload"->>$1::text = $2`,
+			wantArgs: []any{"currency", "USD"},
+		},
+		{
+			name:     "jsonb nested path",
+			sel:      Select{Table: "t", Filters: map[string]any{"payload -> bonus ->> id": "b-1"}},
+			wantSQL:  `SELECT * FROM "t" WHERE "payload"->$1::text->>$2::text = $3`,
+			wantArgs: []any{"bonus", "id", "b-1"},
+		},
+		{
+			name:     "jsonb array index",
+			sel:      Select{Table: "t", Filters: map[string]any{"data->0->>id": "x"}},
+			wantSQL:  `SELECT * FROM "t" WHERE "data"->$1::int->>$2::text = $3`,
+			wantArgs: []any{0, "id", "x"},
+		},
+		{
+			name:     "jsonb negative array index",
+			sel:      Select{Table: "t", Filters: map[string]any{"items->>-1": "last"}},
+			wantSQL:  `SELECT * FROM "t" WHERE "items"->>$1::int = $2`,
+			wantArgs: []any{-1, "last"},
+		},
+		{
+			name:     "jsonb key with quotes is bound",
+			sel:      Select{Table: "t", Filters: map[string]any{"payload->>it's": 1}},
+			wantSQL:  `SELECT * FROM "t" WHERE "payload"->>$1::text = $2`,
+			wantArgs: []any{"it's", 1},
+		},
+		{
+			name: "comparison operators",
+			sel: Select{Table: "t", Filters: map[string]any{
+				"amount": map[string]any{"$gte": 10, "$lt": 20, "$ne": 15},
+			}},
+			wantSQL:  `SELECT * FROM "t" WHERE "amount" >= $1 AND "amount" < $2 AND "amount" <> $3`,
+			wantArgs: []any{10, 20, 15},
+		},
+		{
+			name: "remaining operators",
+			sel: Select{Table: "t", Filters: map[string]any{
+				"a": map[string]any{"$eq": 1},
+				"b": map[string]any{"$gt": 1, "$lte": 5},
+				"c": map[string]any{"$like": "bonus-%"},
+				"d": map[string]any{"$ilike": "%PROMO%"},
+			}},
+			wantSQL:  `SELECT * FROM "t" WHERE "a" = $1 AND "b" > $2 AND "b" <= $3 AND "c" LIKE $4 AND "d" ILIKE $5`,
+			wantArgs: []any{1, 1, 5, "bonus-%", "%PROMO%"},
+		},
+		{
+			name: "in and not in",
+			sel: Select{Table: "t", Filters: map[string]any{
+				"status": map[string]any{"$in": []any{"active", "pending"}},
+				"type":   map[string]any{"$nin": []any{"test"}},
+			}},
+			wantSQL:  `SELECT * FROM "t" WHERE "status" IN ($1, $2) AND "type" NOT IN ($3)`,
+			wantArgs: []any{"active", "pending", "test"},
+		},
+		{
+			name: "empty in lists",
+			sel: Select{Table: "t", Filters: map[string]any{
+				"a": map[string]any{"$in": []any{}},
+				"b": map[string]any{"$nin": []any{}},
+			}},
+			wantSQL: `SELECT * FROM "t" WHERE FALSE AND TRUE`,
+		},
+		{
+			name: "null checks",
+			sel: Select{Table: "t", Filters: map[string]any{
+				"deleted_at": map[string]any{"$null": true},
+				"user_id":    map[string]any{"$null": false},
+			}},
+			wantSQL: `SELECT * FROM "t" WHERE "deleted_at" IS NULL AND "user_id" IS NOT NULL`,
+		},
+		{
+			name:     "object without operators compares as a value",
+			sel:      Select{Table: "t", Filters: map[string]any{"meta": map[string]any{"a": 1}}},
+			wantSQL:  `SELECT * FROM "t" WHERE "meta" = $1`,
+			wantArgs: []any{map[string]any{"a": 1}},
+		},
+		{
+			name:     "order by and limit",
+			sel:      Select{Table: "t", Filters: map[string]any{"user_id": 1}, OrderBy: []string{"created_at desc", "id", "payload->>rank ASC"}, Limit: 1},
+			wantSQL:  `SELECT * FROM "t" WHERE "user_id" = $1 ORDER BY "created_at" DESC, "id", "payload"->>$2::text ASC LIMIT 1`,
+			wantArgs: []any{1, "rank"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qb, err := BuildSelect(tt.sel)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if qb.SQL != tt.wantSQL {
+				t.Errorf("SQL\n got %s\nwant %s", qb.SQL, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(qb.Args, tt.wantArgs) {
+				t.Errorf("args %#v, want %#v", qb.Args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestBuildSelectErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		sel     Select
+		wantErr string
+	}{
+		{name: "missing table", sel: Select{}, wantErr: "table is required"},
+		{name: "empty jsonb segment", sel: Select{Table: "t", Filters: map[string]any{"payload->>": 1}}, wantErr: `invalid column "payload->>"`},
+		{name: "missing column", sel: Select{Table: "t", Filters: map[string]any{"->>a": 1}}, wantErr: `invalid column "->>a"`},
+		{name: "unknown operator", sel: Select{Table: "t", Filters: map[string]any{"a": map[string]any{"$between": 1}}}, wantErr: "column a: unsupported operator $between"},
+		{name: "comparison with null", sel: Select{Table: "t", Filters: map[string]any{"a": map[string]any{"$ne": nil}}}, wantErr: "use $null to match NULL"},
+		{name: "in without a list", sel: Select{Table: "t", Filters: map[string]any{"a": map[string]any{"$in": "x"}}}, wantErr: "$in needs a list"},
+		{name: "null without a bool", sel: Select{Table: "t", Filters: map[string]any{"a": map[string]any{"$null": "yes"}}}, wantErr: "$null needs true or false"},
+		{name: "order by direction", sel: Select{Table: "t", OrderBy: []string{"id sideways"}}, wantErr: `invalid orderBy direction "sideways"`},
+		{name: "order by too many words", sel: Select{Table: "t", OrderBy: []string{"id desc nulls"}}, wantErr: `invalid orderBy "id desc nulls"`},
+		{name: "order by empty", sel: Select{Table: "t", OrderBy: []string{" "}}, wantErr: `invalid orderBy " "`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BuildSelect(tt.sel)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBindSQL(t *testing.T) {
+	vars := map[string]string{"userId": "1", "bonusId": "b-1", "name": "O'Brien"}
+	tests := []struct {
+		name     string
+		sql      string
+		wantSQL  string
+		wantArgs []any
+		wantErr  string
+	}{
+		{
+			name:     "placeholders become parameters",
+			sql:      "SELECT * FROM bonus_wallets WHERE user_id = ${userId} AND bonus_id = ${bonusId}",
+			wantSQL:  "SELECT * FROM bonus_wallets WHERE user_id = $1 AND bonus_id = $2",
+			wantArgs: []any{"1", "b-1"},
+		},
+		{
+			name:     "repeated variables share a parameter",
+			sql:      "SELECT ${userId}, ${bonusId}, ${userId}",
+			wantSQL:  "SELECT $1, $2, $1",
+			wantArgs: []any{"1", "b-1"},
+		},
+		{
+			name:     "values never reach the statement",
+			sql:      "SELECT * FROM users WHERE name = ${name}",
+			wantSQL:  "SELECT * FROM users WHERE name = $1",
+			wantArgs: []any{"O'Brien"},
+		},
+		{
+			name:     "string literals without placeholders are kept",
+			sql:      "SELECT * FROM t WHERE status = 'it''s' AND note <> '' AND id = ${userId}",
+			wantSQL:  "SELECT * FROM t WHERE status = 'it''s' AND note <> '' AND id = $1",
+			wantArgs: []any{"1"},
+		},
+		{
+			name:    "no placeholders",
+			sql:     "SELECT 1",
+			wantSQL: "SELECT 1",
+		},
+		{name: "quoted placeholder", sql: "SELECT * FROM t WHERE id = '${userId}'", wantErr: "placeholder ${userId} is inside a string literal"},
+		{name: "placeholder in a longer literal", sql: "SELECT * FROM t WHERE note LIKE 'bonus ${bonusId}%'", wantErr: "placeholder ${bonusId} is inside a string literal"},
+		{name: "placeholder after an escaped quote", sql: "SELECT 'it''s ${userId}'", wantErr: "inside a string literal"},
+		{name: "placeholder in an unterminated literal", sql: "SELECT 'open ${userId}", wantErr: "inside a string literal"},
+		{
+			name:     "quoted regions without placeholders are kept",
+			sql:      "SELECT $$it's$$, $fn$a 'b'$fn$, E'it\\'s', \"it's\" -- it's\nFROM t /* it's /* nested */ */ WHERE a$b = ${userId}",
+			wantSQL:  "SELECT $$it's$$, $fn$a 'b'$fn$, E'it\\'s', \"it's\" -- it's\nFROM t /* it's /* nested */ */ WHERE a$b = $1",
+			wantArgs: []any{"1"},
+		},
+		{
+			name:     "placeholder after a line comment",
+			sql:      "SELECT * FROM t -- by user\nWHERE id = ${userId}",
+			wantSQL:  "SELECT * FROM t -- by user\nWHERE id = $1",
+			wantArgs: []any{"1"},
+		},
+		{name: "placeholder in a dollar-quoted string", sql: "SELECT $$bonus ${bonusId}$$", wantErr: "placeholder ${bonusId} is inside a dollar-quoted string"},
+		{name: "placeholder in a tagged dollar-quoted string", sql: "SELECT $body$ $$ ${bonusId} $body$", wantErr: "inside a dollar-quoted string"},
+		{name: "placeholder in an unterminated dollar-quoted string", sql: "SELECT $$ ${bonusId}", wantErr: "inside a dollar-quoted string"},
+		{name: "placeholder after an escaped quote in an escape string", sql: `SELECT E'it\'s ${userId}'`, wantErr: "placeholder ${userId} is inside a string literal"},
+		{name: "placeholder in a quoted identifier", sql: `SELECT "${userId}" FROM t`, wantErr: "inside a quoted identifier"},
+		{name: "placeholder in a line comment", sql: "SELECT 1 -- ${userId}", wantErr: "placeholder ${userId} is inside a comment"},
+		{name: "placeholder in a block comment", sql: "SELECT 1 /* a /* b */ ${userId} */", wantErr: "placeholder ${userId} is inside a comment"},
+		{name: "placeholder after a quote in a comment", sql: "SELECT 1 /* it's */ , '${userId}'", wantErr: "inside a string literal"},
+		{name: "undefined variables", sql: "SELECT ${a}, ${userId}, ${b}, ${a}", wantErr: "sql uses undefined variables a, b"},
+		{name: "empty statement", sql: "  ", wantErr: "sql is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qb, err := BindSQL(tt.sql, vars)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if qb.SQL != tt.wantSQL {
+				t.Errorf("SQL\n got %s\nwant %s", qb.SQL, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(qb.Args, tt.wantArgs) {
+				t.Errorf("args %#v, want %#v", qb.Args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/example/go-test-framework/framework/db"
+	"github.com/example/go-test-framework/framework/match"
+)
+
+// Client executes key lookups and validations against Redis.
+type Client struct {
+	rdb *goredis.Client
+}
+
+// New connects to addr, either host:port or a redis:// URL carrying
+// credentials and the database number.
+func New(ctx context.Context, addr string) (*Client, error) {
+	if addr == "" {
+		return nil, errors.New("redis address is required")
+	}
+	opts := &goredis.Options{Addr: addr}
+	if strings.Contains(addr, "://") {
+		var err error
+		if opts, err = goredis.ParseURL(addr); err != nil {
+			return nil, err
+		}
+	}
+	return &Client{rdb: goredis.NewClient(opts)}, nil
+}
+
+func (c *Client) Close() error {
+	if c == nil || c.rdb == nil {
+		return nil
+	}
+	return c.rdb.Close()
+}
+
+func (c *Client) ValidateExists(ctx context.Context, key string, expected bool) error {
+	n, err := c.rdb.Exists(ctx, key).Result()
+	if err != nil {
+		return &db.QueryError{Err: err}
+	}
+	if exists := n > 0; exists != expected {
+		if expected {
+			return fmt.Errorf("key %s does not exist", key)
+		}
+		return fmt.Errorf("key %s exists, want it to be absent", key)
+	}
+	return nil
+}
+
+// ValidateValue checks a string key. Numeric values compare as numbers and
+// JSON documents are decoded, so matchers and partial objects work.
+func (c *Client) ValidateValue(ctx context.Context, key string, expected any) error {
+	val, err := c.rdb.Get(ctx, key).Result()
+	present := true
+	if errors.Is(err, goredis.Nil) {
+		present, err = false, nil
+	}
+	if err != nil {
+		return &db.QueryError{Err: err}
+	}
+	if err := match.Field(decodeValue(val), present, expected); err != nil {
+		return fmt.Errorf("key %s: %w", key, err)
+	}
+	return nil
+}
+
+// ValidateFields checks hash fields of key against expected.
+func (c *Client) ValidateFields(ctx context.Context, key string, expected map[string]any) error {
+	fields, err := c.rdb.HGetAll(ctx, key).Result()
+	if err != nil {
+		return &db.QueryError{Err: err}
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("hash %s does not exist or is empty", key)
+	}
+	for _, name := range sortedKeys(expected) {
+		val, ok := fields[name]
+		if err := match.Field(decodeValue(val), ok, expected[name]); err != nil {
+			return fmt.Errorf("hash %s field %s: %w", key, name, err)
+		}
+	}
+	return nil
+}
+
+// ValidateMembers checks that every expected member is in the set, sorted
+// set or list stored at key.
+func (c *Client) ValidateMembers(ctx context.Context, key string, expected []string) error {
+	members, err := c.members(ctx, key)
+	if err != nil {
+		return err
+	}
+	have := make(map[string]bool, len(members))
+	for _, m := range members {
+		have[m] = true
+	}
+	var missing []string
+	for _, m := range expected {
+		if !have[m] {
+			missing = append(missing, m)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s is missing members %s (has %s)", key, match.Format(missing), match.Format(members))
+	}
+	return nil
+}
+
+// ValidateLen checks the number of elements of the collection at key, or
+// the length of a string value.
+func (c *Client) ValidateLen(ctx context.Context, key string, expected int) error {
+	kind, err := c.rdb.Type(ctx, key).Result()
+	if err != nil {
+		return &db.QueryError{Err: err}
+	}
+	var n int64
+	switch kind {
+	case "string":
+		n, err = c.rdb.StrLen(ctx, key).Result()
+	case "list":
+		n, err = c.rdb.LLen(ctx, key).Result()
+	case "set":
+		n, err = c.rdb.SCard(ctx, key).Result()
+	case "zset":
+		n, err = c.rdb.ZCard(ctx, key).Result()
+	case "hash":
+		n, err = c.rdb.HLen(ctx, key).Result()
+	case "none":
+	default:
+		return fmt.Errorf("key %s has unsupported type %s", key, kind)
+	}
+	if err != nil {
+		return &db.QueryError{Err: err}
+	}
+	if int(n) != expected {
+		return fmt.Errorf("expected %s to have %d elements, got %d", key, expected, n)
+	}
+	return nil
+}
+
+// ValidateTTL checks that the remaining time to live of key lies within
+// [min, max]; a zero max means no upper bound. Keys without expiry fail.
+func (c *Client) ValidateTTL(ctx context.Context, key string, min, max time.Duration) error {
+	ttl, err := c.rdb.PTTL(ctx, key).Result()
+	if err != nil {
+		return &db.QueryError{Err: err}
+	}
+	switch {
+	case ttl == -2:
+		return fmt.Errorf("key %s does not exist", key)
+	case ttl < 0:
+		return fmt.Errorf("key %s has no expiry", key)
+	case ttl < min || (max > 0 && ttl > max):
+		return fmt.Errorf("key %s ttl %s outside [%s, %s]", key, ttl.Round(time.Millisecond), min, formatMax(max))
+	}
+	return nil
+}
+
+// ValidateCount checks the number of keys matching a glob pattern. Keys are
+// counted with SCAN so large databases are not blocked; SCAN may return a key
+// more than once, so each is counted once.
+func (c *Client) ValidateCount(ctx context.Context, pattern string, expected int) error {
+	keys := map[string]struct{}{}
+	iter := c.rdb.Scan(ctx, 0, pattern, 1000).Iterator()
+	for iter.Next(ctx) {
+		keys[iter.Val()] = struct{}{}
+	}
+	if err := iter.Err(); err != nil {
+		return &db.QueryError{Err: err}
+	}
+	if n := len(keys); n != expected {
+		return fmt.Errorf("expected %d keys matching %s, got %d", expected, pattern, n)
+	}
+	return nil
+}
+
+func (c *Client) members(ctx context.Context, key string) ([]string, error) {
+	kind, err := c.rdb.Type(ctx, key).Result()
+	if err != nil {
+		return nil, &db.QueryError{Err: err}
+	}
+	var members []string
+	switch kind {
+	case "set":
+		members, err = c.rdb.SMembers(ctx, key).Result()
+	case "zset":
+		members, err = c.rdb.ZRange(ctx, key, 0, -1).Result()
+	case "list":
+		members, err = c.rdb.LRange(ctx, key, 0, -1).Result()
+	case "none":
+		return nil, fmt.Errorf("key %s does not exist", key)
+	default:
+		return nil, fmt.Errorf("key %s is a %s, want set, sorted set or list", key, kind)
+	}
+	if err != nil {
+		return nil, &db.QueryError{Err: err}
+	}
+	return members, nil
+}
+
+// decodeValue turns numeric strings into json.Number and JSON documents into
+// maps and slices; anything else stays a string.
+func decodeValue(s string) any {
+	trimmed := strings.TrimSpace(s)
+	if _, err := strconv.ParseFloat(trimmed, 64); err == nil {
+		return json.Number(trimmed)
+	}
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		dec := json.NewDecoder(strings.NewReader(trimmed))
+		dec.UseNumber()
+		var v any
+		if dec.Decode(&v) == nil {
+			return v
+		}
+	}
+	return s
+}
+
+func formatMax(max time.Duration) string {
+	if max <= 0 {
+		return "∞"
+	}
+	return max.String()
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,122 @@
+package declarative
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/example/go-test-framework/framework/match"
+	"github.com/example/go-test-framework/framework/mq"
+)
+
+const defaultMessageTimeout = 5 * time.Second
+
+// maxCapturedListed caps the captured items listed in a failure message.
+const maxCapturedListed = 5
+
+// subscribeEach opens a subscription per assertion before the step's action
+// runs, so nothing published by the action is missed. On error the
+// subscriptions opened so far are closed.
+func subscribeEach[A any, S io.Closer](assertions []A, open func(A) (S, error)) ([]S, error) {
+	subs := make([]S, 0, len(assertions))
+	for _, a := range assertions {
+		sub, err := open(a)
+		if err != nil {
+			closeAll(subs)
+			return nil, permanentError{err}
+		}
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+func closeAll[S io.Closer](subs []S) {
+	for _, sub := range subs {
+		_ = sub.Close()
+	}
+}
+
+// capture checks the items collected by a subscription, such as RabbitMQ
+// messages or Kafka records.
+type capture[T any] struct {
+	noun     string
+	buf      *mq.Buffer[T]
+	mismatch func(T) error
+	describe func(T) string
+}
+
+// await waits up to timeout for matching items. Without count the first
+// match passes; with count the whole timeout is waited so extra items are
+// caught. Failures list the captured items and why they do not match.
+func (c capture[T]) await(ctx context.Context, count *int, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = defaultMessageTimeout
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	matching := func(items []T) int {
+		n := 0
+		for _, item := range items {
+			if c.mismatch(item) == nil {
+				n++
+			}
+		}
+		return n
+	}
+	items := c.buf.Wait(waitCtx, func(items []T) bool {
+		if count == nil {
+			return matching(items) > 0
+		}
+		return matching(items) > *count
+	})
+
+	got := matching(items)
+	switch {
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case count == nil && got == 0:
+		return fmt.Errorf("no matching %s within %s%s", c.noun, timeout, c.list(items))
+	case count != nil && got != *count:
+		return fmt.Errorf("expected %d matching %ss within %s, got %d%s", *count, c.noun, timeout, got, c.list(items))
+	}
+	return nil
+}
+
+func (c capture[T]) list(items []T) string {
+	if len(items) == 0 {
+		return fmt.Sprintf("; no %ss received", c.noun)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "; received %d:", len(items))
+	for i, item := range items {
+		if i == maxCapturedListed {
+			fmt.Fprintf(&b, "\n  ... %d more", len(items)-i)
+			break
+		}
+		b.WriteString("\n  ")
+		b.WriteString(c.describe(item))
+		if err := c.mismatch(item); err != nil {
+			fmt.Fprintf(&b, " (%v)", err)
+		}
+	}
+	return b.String()
+}
+
+// matchHeaders checks every expected header, in name order.
+func matchHeaders(have, expected map[string]any) error {
+	names := make([]string, 0, len(expected))
+	for name := range expected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v, ok := have[name]
+		if err := match.Field(v, ok, expected[name]); err != nil {
+			return fmt.Errorf("header %s: %w", name, err)
+		}
+	}
+	return nil
+}
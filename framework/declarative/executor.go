@@ -2,160 +2,546 @@ package declarative
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/example/go-test-framework/framework/db"
 	"github.com/example/go-test-framework/framework/db/mongo"
+	"github.com/example/go-test-framework/framework/db/pool"
 	"github.com/example/go-test-framework/framework/db/postgres"
+	"github.com/example/go-test-framework/framework/db/redis"
 	httpclient "github.com/example/go-test-framework/framework/http"
+	"github.com/example/go-test-framework/framework/jsonpath"
+	"github.com/example/go-test-framework/framework/match"
+	"github.com/example/go-test-framework/framework/mq/kafka"
+	"github.com/example/go-test-framework/framework/mq/rabbitmq"
+	"github.com/example/go-test-framework/framework/result"
+	"github.com/example/go-test-framework/framework/script"
 	"github.com/example/go-test-framework/framework/utils"
 )
 
-// Executor orchestrates declarative tests end-to-end.
+// Executor orchestrates declarative tests end-to-end. Script steps run in
+// WorkDir unless their spec sets a directory.
+//
+// Database assertions use DB, the suite's client pool, for databases declared
+// in the suite environment and fall back to the Postgres, Mongo, Redis,
+// RabbitMQ and Kafka clients.
 type Executor struct {
 	HTTP     *httpclient.Client
 	Postgres *postgres.Client
 	Mongo    *mongo.Client
+	Redis    *redis.Client
+	RabbitMQ rabbitmq.Broker
+	Kafka    kafka.Broker
+	DB       *pool.Pool
 	Logger   *utils.StructuredLogger
+	WorkDir  string
 }
 
-func (e *Executor) Run(ctx context.Context, test DeclarativeTest, execCtx *utils.ExecutionContext) error {
-	if e.HTTP == nil {
-		return errors.New("http client must be configured")
+// Run executes every step of test in order and returns one StepResult per
+// step; steps after a failure are reported as skipped.
+func (e *Executor) Run(ctx context.Context, test DeclarativeTest, execCtx *utils.ExecutionContext) ([]result.StepResult, error) {
+	log := utils.LoggerFromContext(ctx, e.Logger).With("test", test.Name)
+	log.Info("starting declarative test", map[string]any{"description": test.Description})
+
+	steps, err := test.ExecutionSteps()
+	if err != nil {
+		return []result.StepResult{{Name: test.Name, Status: result.StatusError, Failure: err.Error()}}, permanentError{err}
 	}
-	if e.Logger == nil {
-		e.Logger = utils.NewLogger()
+	results := make([]result.StepResult, len(steps))
+	for i, step := range steps {
+		results[i] = result.StepResult{Name: step.Name, Status: result.StatusSkip}
+	}
+	for i, step := range steps {
+		label := stepLabel(i, step)
+		stepLog := log.With("step", label)
+		if len(steps) > 1 {
+			stepLog.Info("starting step", nil)
+		}
+		started := time.Now()
+		err := e.runStep(ctx, step, execCtx, stepLog, &results[i])
+		results[i].Duration = time.Since(started)
+		if err != nil {
+			results[i].Status = failureStatus(err)
+			results[i].Failure = err.Error()
+			stepLog.Error("step failed", map[string]any{"error": err.Error()})
+			return results, fmt.Errorf("step %s failed: %w", label, err)
+		}
+		results[i].Status = result.StatusPass
 	}
 
-	log := e.Logger.With("test", test.Name)
-	log.Info("starting declarative test", map[string]any{"description": test.Description})
+	log.Info("declarative test finished", nil)
+	return results, nil
+}
 
-	if err := e.executeAction(ctx, test, execCtx, log); err != nil {
-		log.Error("action failed", map[string]any{"error": err.Error()})
+func (e *Executor) runStep(ctx context.Context, step Step, execCtx *utils.ExecutionContext, log *utils.StructuredLogger, res *result.StepResult) error {
+	subs, err := e.subscribe(ctx, step.Messages, execCtx.Snapshot())
+	if err != nil {
+		return err
+	}
+	defer closeAll(subs)
+	recordSubs, err := e.subscribeRecords(ctx, step.Records, execCtx.Snapshot())
+	if err != nil {
 		return err
 	}
+	defer closeAll(recordSubs)
 
-	if test.DelayAfter > 0 {
-		log.Info("sleeping after action", map[string]any{"delay": test.DelayAfter.String()})
+	execute := e.executeAction
+	switch {
+	case step.Script != nil:
+		execute = e.executeScript
+	case step.Publish != nil:
+		execute = e.executePublish
+	case step.Produce != nil:
+		execute = e.executeProduce
+	}
+	if err := execute(ctx, step, execCtx, log, res); err != nil {
+		return err
+	}
+
+	if step.DelayAfter > 0 {
+		log.Info("sleeping after action", map[string]any{"delay": step.DelayAfter.String()})
 		select {
-		case <-time.After(test.DelayAfter):
+		case <-time.After(step.DelayAfter):
 		case <-ctx.Done():
 			return ctx.Err()
 		}
 	}
 
-	for _, assertion := range test.Assertions {
-		if err := e.executeAssertion(ctx, assertion, execCtx, log); err != nil {
+	for _, assertion := range step.Assertions {
+		outcome, err := e.executeAssertion(ctx, assertion, execCtx, log)
+		res.Assertions = append(res.Assertions, outcome)
+		if err != nil {
+			return err
+		}
+	}
+	for i, assertion := range step.Messages {
+		outcome, err := e.executeMessages(ctx, subs[i], assertion, execCtx)
+		res.Assertions = append(res.Assertions, outcome)
+		if err != nil {
+			return err
+		}
+	}
+	for i, assertion := range step.Records {
+		outcome, err := e.executeRecords(ctx, recordSubs[i], assertion, execCtx)
+		res.Assertions = append(res.Assertions, outcome)
+		if err != nil {
 			return err
 		}
 	}
-
-	log.Info("declarative test finished", nil)
 	return nil
 }
 
-func (e *Executor) executeAction(ctx context.Context, test DeclarativeTest, execCtx *utils.ExecutionContext, log *utils.StructuredLogger) error {
+func stepLabel(idx int, step Step) string {
+	if step.Name == "" {
+		return fmt.Sprintf("#%d", idx+1)
+	}
+	return fmt.Sprintf("#%d %q", idx+1, step.Name)
+}
+
+// failureStatus separates mismatches in the system under test (fail) from
+// environment problems and timeouts (error).
+func failureStatus(err error) result.Status {
+	var permanent permanentError
+	if errors.As(err, &permanent) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return result.StatusError
+	}
+	return result.StatusFail
+}
+
+func (e *Executor) executeAction(ctx context.Context, step Step, execCtx *utils.ExecutionContext, log *utils.StructuredLogger, res *result.StepResult) error {
+	if e.HTTP == nil {
+		return permanentError{errors.New("http client must be configured")}
+	}
+	vars := execCtx.Snapshot()
 	requestBody := map[string]any{}
-	if test.Action.Body != nil {
-		requestBody = utils.CloneMap(test.Action.Body)
-		if substituted, ok := utils.Substitute(requestBody, execCtx.Snapshot()).(map[string]any); ok {
+	if step.Action.Body != nil {
+		requestBody = utils.CloneMap(step.Action.Body)
+		if substituted, ok := utils.Substitute(requestBody, vars).(map[string]any); ok {
 			requestBody = substituted
 		}
 	}
 	headers := map[string]string{}
-	for k, v := range test.Action.Headers {
-		headers[k] = v
+	for k, v := range step.Action.Headers {
+		headers[k] = utils.Substitute(v, vars).(string)
 	}
+	endpoint := utils.Substitute(step.Action.Endpoint, vars).(string)
 
-	resp, err := e.HTTP.Do(ctx, httpclient.Request{
-		Service:  test.Action.Service,
-		Method:   strings.ToUpper(test.Action.Method),
-		Endpoint: test.Action.Endpoint,
+	req := httpclient.Request{
+		Service:  step.Action.Service,
+		Method:   strings.ToUpper(step.Action.Method),
+		Endpoint: endpoint,
 		Body:     requestBody,
 		Headers:  headers,
-	})
+	}
+	res.Request = &result.HTTPRequest{Method: req.Method, URL: req.Service + req.Endpoint, Headers: headers}
+	if len(requestBody) > 0 {
+		if data, err := json.Marshal(requestBody); err == nil {
+			res.Request.Body = string(data)
+		}
+	}
+
+	resp, err := e.HTTP.Do(ctx, req)
 	if err != nil {
-		return err
+		return permanentError{err}
 	}
+	res.Request.URL = resp.URL
+	res.Response = &result.HTTPResponse{StatusCode: resp.StatusCode, Body: string(resp.Raw)}
 
-	if test.ResponseAssertions != nil {
-		if err := validateResponse(resp, test.ResponseAssertions); err != nil {
+	if step.ResponseAssertions != nil {
+		err := validateResponse(resp, step.ResponseAssertions, vars)
+		outcome := result.AssertionResult{Target: "response", Status: result.StatusPass, Attempts: 1}
+		if err != nil {
+			outcome.Status, outcome.Diff = result.StatusFail, err.Error()
+		}
+		res.Assertions = append(res.Assertions, outcome)
+		if err != nil {
 			return err
 		}
 	}
 
-	for varName, path := range test.Action.Extract {
-		if value, ok := resp.Body[path]; ok {
-			execCtx.Set(varName, fmt.Sprint(value))
-			log.Info("extracted variable", map[string]any{"key": varName, "value": value})
+	var doc any
+	if len(step.Action.Extract) > 0 {
+		doc = resp.JSON()
+	}
+	for varName, path := range step.Action.Extract {
+		value, err := jsonpath.Get(doc, path)
+		if err != nil {
+			return fmt.Errorf("extract %s: %w", varName, err)
 		}
+		execCtx.Set(varName, stringifyValue(value))
+		log.Info("extracted variable", map[string]any{"key": varName, "value": value})
 	}
 	return nil
 }
 
-func validateResponse(resp *httpclient.Response, expectations *ResponseAssertions) error {
+func (e *Executor) executeScript(ctx context.Context, step Step, execCtx *utils.ExecutionContext, log *utils.StructuredLogger, res *result.StepResult) error {
+	out, err := script.Run(ctx, *step.Script, e.WorkDir, script.Env(ctx, execCtx.Snapshot()))
+	if out != nil {
+		res.Command = &result.CommandRun{Command: out.Command, ExitCode: out.ExitCode, Stdout: out.Stdout, Stderr: out.Stderr}
+	}
+	if err != nil {
+		return permanentError{err}
+	}
+	if err := step.Script.Check(out); err != nil {
+		return err
+	}
+	vars, err := step.Script.ExtractVars(out)
+	if err != nil {
+		return err
+	}
+	for k, v := range vars {
+		execCtx.Set(k, v)
+		log.Info("extracted variable", map[string]any{"key": k, "value": v})
+	}
+	return nil
+}
+
+// stringifyValue renders extracted values for the string-only execution
+// context: scalars as-is, objects and arrays as JSON.
+func stringifyValue(value any) string {
+	switch value.(type) {
+	case map[string]any, []any:
+		data, err := json.Marshal(value)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+func validateResponse(resp *httpclient.Response, expectations *ResponseAssertions, vars map[string]string) error {
 	if resp.StatusCode != expectations.Status {
 		return fmt.Errorf("unexpected status: got %d want %d", resp.StatusCode, expectations.Status)
 	}
 	if expectations.Body != nil && len(expectations.Body.Contains) > 0 {
-		for key, val := range expectations.Body.Contains {
-			if actual, ok := resp.Body[key]; !ok || fmt.Sprint(actual) != fmt.Sprint(val) {
-				return fmt.Errorf("response body missing %s=%v", key, val)
+		contains, _ := utils.Substitute(expectations.Body.Contains, vars).(map[string]any)
+		if err := matchPaths(resp.JSON(), contains); err != nil {
+			return fmt.Errorf("response body: %w", err)
+		}
+	}
+	return nil
+}
+
+// matchPaths checks every path expression of contains against doc.
+func matchPaths(doc any, contains map[string]any) error {
+	paths := make([]string, 0, len(contains))
+	for path := range contains {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		val := contains[path]
+		p, err := jsonpath.Compile(path)
+		if err != nil {
+			return err
+		}
+		actual, lookupErr := p.Get(doc)
+		if err := match.Field(actual, lookupErr == nil, val); err != nil {
+			if lookupErr != nil && !match.IsMatcher(val) {
+				return lookupErr
 			}
+			return fmt.Errorf("%s: %w", path, err)
 		}
 	}
 	return nil
 }
 
-func (e *Executor) executeAssertion(ctx context.Context, assertion Assertion, execCtx *utils.ExecutionContext, log *utils.StructuredLogger) error {
+const (
+	defaultEventuallyTimeout  = 10 * time.Second
+	defaultEventuallyInterval = 200 * time.Millisecond
+)
+
+// permanentError marks failures caused by the environment rather than the
+// system under test, such as a missing client or a transport error. They are
+// reported as errors and never polled.
+type permanentError struct {
+	err error
+}
+
+func (p permanentError) Error() string { return p.err.Error() }
+func (p permanentError) Unwrap() error { return p.err }
+
+func (e *Executor) executeAssertion(ctx context.Context, assertion Assertion, execCtx *utils.ExecutionContext, log *utils.StructuredLogger) (result.AssertionResult, error) {
+	outcome := result.AssertionResult{Target: assertionTarget(assertion), Status: result.StatusPass}
+	outcome.Query, _ = utils.Substitute(assertion.Query, execCtx.Snapshot()).(map[string]any)
+	switch {
+	case assertion.SQL != "":
+		outcome.Query = map[string]any{"sql": assertion.SQL}
+		if stmt, err := postgres.BindSQL(assertion.SQL, execCtx.Snapshot()); err == nil {
+			outcome.Query = map[string]any{"sql": stmt.SQL, "args": stmt.Args}
+		}
+	case len(assertion.Pipeline) > 0:
+		outcome.Query = map[string]any{"pipeline": substitutePipeline(assertion.Pipeline, execCtx.Snapshot())}
+	}
+	err := e.pollAssertion(ctx, assertion, execCtx, log, &outcome.Attempts)
+	if err != nil {
+		outcome.Status, outcome.Diff = failureStatus(err), err.Error()
+	}
+	return outcome, err
+}
+
+func substitutePipeline(pipeline []json.RawMessage, vars map[string]string) []json.RawMessage {
+	out := make([]json.RawMessage, len(pipeline))
+	for i, stage := range pipeline {
+		out[i] = utils.SubstituteJSON(stage, vars)
+	}
+	return out
+}
+
+func assertionTarget(assertion Assertion) string {
+	switch {
+	case assertion.Key != "":
+		return fmt.Sprintf("%s %s", assertion.Database, assertion.Key)
+	case assertion.Pattern != "":
+		return fmt.Sprintf("%s %s", assertion.Database, assertion.Pattern)
+	case assertion.SQL != "":
+		return assertion.Database + " sql"
+	case assertion.Collection != "":
+		db := assertion.DatabaseName
+		if db == "" {
+			db = assertion.Schema
+		}
+		return fmt.Sprintf("%s %s.%s", assertion.Database, db, assertion.Collection)
+	case assertion.Schema != "":
+		return fmt.Sprintf("%s %s.%s", assertion.Database, assertion.Schema, assertion.Table)
+	}
+	return fmt.Sprintf("%s %s", assertion.Database, assertion.Table)
+}
+
+func (e *Executor) pollAssertion(ctx context.Context, assertion Assertion, execCtx *utils.ExecutionContext, log *utils.StructuredLogger, attempts *int) error {
+	if assertion.Eventually == nil {
+		*attempts = 1
+		return e.checkAssertion(ctx, assertion, execCtx, log)
+	}
+
+	timeout := assertion.Eventually.Timeout
+	if timeout <= 0 {
+		timeout = defaultEventuallyTimeout
+	}
+	interval := assertion.Eventually.Interval
+	if interval <= 0 {
+		interval = defaultEventuallyInterval
+	}
+	backoff := assertion.Eventually.Backoff
+	if backoff < 1 {
+		backoff = 1
+	}
+
+	deadline := time.Now().Add(timeout)
+	for attempt := 1; ; attempt++ {
+		*attempts = attempt
+		err := e.checkAssertion(ctx, assertion, execCtx, log)
+		if err == nil {
+			return nil
+		}
+		var permanent permanentError
+		if errors.As(err, &permanent) {
+			return err
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("assertion did not hold within %s after %d attempts, last result: %w", timeout, attempt, err)
+		}
+		log.Info("assertion not satisfied yet", map[string]any{"attempt": attempt, "error": err.Error(), "retryIn": interval.String()})
+
+		wait := interval
+		if wait > remaining {
+			wait = remaining
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return fmt.Errorf("%w while waiting for assertion, last result: %v", ctx.Err(), err)
+		}
+		interval = time.Duration(float64(interval) * backoff)
+	}
+}
+
+// checkAssertion evaluates assertion once. Driver and query errors are
+// permanent: polling cannot fix a broken query.
+func (e *Executor) checkAssertion(ctx context.Context, assertion Assertion, execCtx *utils.ExecutionContext, log *utils.StructuredLogger) error {
+	err := e.evaluateAssertion(ctx, assertion, execCtx, log)
+	var queryErr *db.QueryError
+	if errors.As(err, &queryErr) {
+		return permanentError{err}
+	}
+	return err
+}
+
+func (e *Executor) evaluateAssertion(ctx context.Context, assertion Assertion, execCtx *utils.ExecutionContext, log *utils.StructuredLogger) error {
+	vars := execCtx.Snapshot()
 	query := map[string]any{}
 	if assertion.Query != nil {
-		substituted := utils.Substitute(assertion.Query, execCtx.Snapshot())
+		substituted := utils.Substitute(assertion.Query, vars)
 		if q, ok := substituted.(map[string]any); ok {
 			query = q
 		}
 	}
+	rows, checkRows := assertion.Expected.RowSpec(vars)
 
 	switch strings.ToLower(assertion.Database) {
 	case "mongodb", "mongo":
-		if e.Mongo == nil {
-			return errors.New("mongo client is not configured")
-		}
 		dbName := assertion.DatabaseName
 		if dbName == "" {
 			dbName = assertion.Schema
 		}
 		if dbName == "" {
-			return errors.New("mongo databaseName is required")
+			return permanentError{errors.New("mongo databaseName is required")}
+		}
+		client, err := e.mongoClient(ctx, dbName)
+		if err != nil {
+			return permanentError{err}
+		}
+		if len(assertion.Pipeline) > 0 {
+			pipeline, err := mongo.Pipeline(substitutePipeline(assertion.Pipeline, vars))
+			if err != nil {
+				return permanentError{err}
+			}
+			if assertion.Expected.Count != nil {
+				if err := client.ValidatePipelineCount(ctx, dbName, assertion.Collection, pipeline, *assertion.Expected.Count); err != nil {
+					return err
+				}
+			}
+			if checkRows {
+				if err := client.ValidatePipelineRows(ctx, dbName, assertion.Collection, pipeline, rows); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		filter, err := mongo.Filter(query)
+		if err != nil {
+			return permanentError{err}
 		}
 		if assertion.Expected.Count != nil {
-			if err := e.Mongo.ValidateCount(ctx, dbName, assertion.Collection, query, int64(*assertion.Expected.Count)); err != nil {
+			if err := client.ValidateCount(ctx, dbName, assertion.Collection, filter, int64(*assertion.Expected.Count)); err != nil {
 				return err
 			}
 		}
-		if len(assertion.Expected.Contains) > 0 {
-			if err := e.Mongo.ValidateContains(ctx, dbName, assertion.Collection, query, assertion.Expected.Contains); err != nil {
+		if checkRows {
+			if err := client.ValidateRows(ctx, dbName, assertion.Collection, filter, rows); err != nil {
 				return err
 			}
 		}
 	case "postgres", "postgresql":
-		if e.Postgres == nil {
-			return errors.New("postgres client is not configured")
+		client, schema, err := e.postgresClient(ctx, assertion.Schema)
+		if err != nil {
+			return permanentError{err}
+		}
+		var stmt postgres.QueryBuilder
+		if assertion.SQL != "" {
+			stmt, err = postgres.BindSQL(assertion.SQL, vars)
+		} else {
+			stmt, err = postgres.BuildSelect(postgres.Select{Schema: schema, Table: assertion.Table, Filters: query, OrderBy: assertion.OrderBy, Limit: assertion.Limit})
+		}
+		if err != nil {
+			return permanentError{err}
 		}
 		if assertion.Expected.Count != nil {
-			if err := e.Postgres.ValidateCount(ctx, assertion.Schema, assertion.Table, query, *assertion.Expected.Count); err != nil {
+			if err := client.ValidateCount(ctx, stmt, *assertion.Expected.Count); err != nil {
 				return err
 			}
 		}
-		if len(assertion.Expected.Contains) > 0 {
-			if err := e.Postgres.ValidateContains(ctx, assertion.Schema, assertion.Table, query, assertion.Expected.Contains); err != nil {
+		if checkRows {
+			if err := client.ValidateRows(ctx, stmt, rows); err != nil {
 				return err
 			}
 		}
+	case "redis":
+		client, err := e.redisClient(ctx)
+		if err != nil {
+			return permanentError{err}
+		}
+		return checkRedis(ctx, client, assertion, vars)
 	default:
-		log.Info("skipping unsupported assertion target", map[string]any{"database": assertion.Database})
+		return permanentError{fmt.Errorf("unsupported assertion database %q", assertion.Database)}
 	}
 	return nil
 }
+
+// postgresClient resolves the client for an assertion Schema. A Schema that
+// names a database declared in the suite environment connects to that
+// database and the table is queried unqualified; any other Schema is a SQL
+// schema on the default connection.
+func (e *Executor) postgresClient(ctx context.Context, schema string) (*postgres.Client, string, error) {
+	switch {
+	case e.DB.HasPostgres(schema):
+		client, err := e.DB.Postgres(ctx, schema)
+		return client, "", err
+	case e.Postgres != nil:
+		return e.Postgres, schema, nil
+	case e.DB != nil:
+		client, err := e.DB.Postgres(ctx, "")
+		return client, schema, err
+	}
+	return nil, "", errors.New("postgres client is not configured")
+}
+
+// mongoClient resolves the client for a database: declared databases use
+// their own credentials, others go through the default connection.
+func (e *Executor) mongoClient(ctx context.Context, database string) (*mongo.Client, error) {
+	switch {
+	case e.DB.HasMongo(database):
+		return e.DB.Mongo(ctx, database)
+	case e.Mongo != nil:
+		return e.Mongo, nil
+	case e.DB != nil:
+		return e.DB.Mongo(ctx, "")
+	}
+	return nil, errors.New("mongo client is not configured")
+}
+
+func (e *Executor) redisClient(ctx context.Context) (*redis.Client, error) {
+	switch {
+	case e.Redis != nil:
+		return e.Redis, nil
+	case e.DB != nil:
+		return e.DB.Redis(ctx)
+	}
+	return nil, errors.New("redis client is not configured")
+}
@@ -0,0 +1,125 @@
+package declarative
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/example/go-test-framework/framework/match"
+	"github.com/example/go-test-framework/framework/mq/kafka"
+	"github.com/example/go-test-framework/framework/result"
+	"github.com/example/go-test-framework/framework/utils"
+)
+
+func (e *Executor) kafkaClient(ctx context.Context) (kafka.Broker, error) {
+	switch {
+	case e.Kafka != nil:
+		return e.Kafka, nil
+	case e.DB != nil:
+		return e.DB.Kafka(ctx)
+	}
+	return nil, errors.New("kafka client is not configured")
+}
+
+func (e *Executor) executeProduce(ctx context.Context, step Step, execCtx *utils.ExecutionContext, log *utils.StructuredLogger, res *result.StepResult) error {
+	client, err := e.kafkaClient(ctx)
+	if err != nil {
+		return permanentError{err}
+	}
+	vars := execCtx.Snapshot()
+	prod := step.Produce
+	rec := kafka.Record{
+		Topic: utils.Substitute(prod.Topic, vars).(string),
+		Key:   utils.Substitute(prod.Key, vars).(string),
+	}
+	for k, v := range prod.Headers {
+		if rec.Headers == nil {
+			rec.Headers = map[string]string{}
+		}
+		rec.Headers[k] = utils.Substitute(v, vars).(string)
+	}
+	switch value := utils.Substitute(prod.Value, vars).(type) {
+	case nil:
+	case string:
+		rec.Value = []byte(value)
+	default:
+		if rec.Value, err = json.Marshal(value); err != nil {
+			return permanentError{fmt.Errorf("encode record value: %w", err)}
+		}
+	}
+
+	res.Request = &result.HTTPRequest{Method: "PRODUCE", URL: rec.Topic, Headers: rec.Headers, Body: string(rec.Value)}
+	if err := client.Produce(ctx, rec); err != nil {
+		return permanentError{fmt.Errorf("produce to %s: %w", rec.Topic, err)}
+	}
+	log.Info("produced record", map[string]any{"topic": rec.Topic, "key": rec.Key})
+	return nil
+}
+
+// subscribeRecords captures the end offsets of every asserted topic, so
+// records already on the topic are ignored.
+func (e *Executor) subscribeRecords(ctx context.Context, assertions []RecordAssertion, vars map[string]string) ([]*kafka.Subscription, error) {
+	if len(assertions) == 0 {
+		return nil, nil
+	}
+	client, err := e.kafkaClient(ctx)
+	if err != nil {
+		return nil, permanentError{err}
+	}
+	return subscribeEach(assertions, func(a RecordAssertion) (*kafka.Subscription, error) {
+		return client.Subscribe(ctx, utils.Substitute(a.Topic, vars).(string))
+	})
+}
+
+func (e *Executor) executeRecords(ctx context.Context, sub *kafka.Subscription, assertion RecordAssertion, execCtx *utils.ExecutionContext) (result.AssertionResult, error) {
+	vars := execCtx.Snapshot()
+	topic := utils.Substitute(assertion.Topic, vars).(string)
+	outcome := result.AssertionResult{
+		Target:   "kafka " + topic,
+		Status:   result.StatusPass,
+		Attempts: 1,
+		Query:    map[string]any{"topic": topic},
+	}
+	key := utils.Substitute(assertion.Key, vars)
+	headers, _ := utils.Substitute(assertion.Headers, vars).(map[string]any)
+	value, _ := utils.Substitute(assertion.Value, vars).(map[string]any)
+
+	err := capture[kafka.Record]{
+		noun: "record",
+		buf:  &sub.Buffer,
+		mismatch: func(rec kafka.Record) error {
+			return recordMismatch(rec, key, headers, value)
+		},
+		describe: func(rec kafka.Record) string {
+			return fmt.Sprintf("%d/%d key=%q %s", rec.Partition, rec.Offset, rec.Key, rec.Value)
+		},
+	}.await(ctx, assertion.Count, assertion.Timeout)
+	if err != nil {
+		outcome.Status, outcome.Diff = failureStatus(err), err.Error()
+	}
+	return outcome, err
+}
+
+// recordMismatch reports why rec does not satisfy the expected key, headers
+// and value paths.
+func recordMismatch(rec kafka.Record, key any, headers, value map[string]any) error {
+	if key != nil {
+		if err := match.Field(rec.Key, rec.Key != "", key); err != nil {
+			return fmt.Errorf("key: %w", err)
+		}
+	}
+	have := make(map[string]any, len(rec.Headers))
+	for k, v := range rec.Headers {
+		have[k] = v
+	}
+	if err := matchHeaders(have, headers); err != nil {
+		return err
+	}
+	if len(value) > 0 {
+		if err := matchPaths(rec.JSON(), value); err != nil {
+			return fmt.Errorf("value: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package declarative
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/go-test-framework/framework/mq/kafka"
+	"github.com/example/go-test-framework/framework/result"
+	"github.com/example/go-test-framework/framework/utils"
+)
+
+func TestProduceAndRecords(t *testing.T) {
+	produce := &ProduceAction{
+		Topic:   "bonus.events",
+		Key:     "${bonusId}",
+		Headers: map[string]string{"type": "bonus.created"},
+		Value:   map[string]any{"bonusId": "${bonusId}", "amount": 100},
+	}
+	tests := []struct {
+		name    string
+		records []RecordAssertion
+		wantErr string
+	}{
+		{
+			name:    "matching record",
+			records: []RecordAssertion{{Topic: "bonus.events", Key: "${bonusId}", Headers: map[string]any{"type": "bonus.created"}, Value: map[string]any{"bonusId": "${bonusId}", "amount": 100}}},
+		},
+		{
+			name:    "matcher on the value",
+			records: []RecordAssertion{{Topic: "bonus.events", Value: map[string]any{"amount": map[string]any{"$gte": 50}}}},
+		},
+		{
+			name:    "exact count",
+			records: []RecordAssertion{{Topic: "bonus.events", Count: ptr(1), Timeout: 50 * time.Millisecond}},
+		},
+		{
+			name:    "count mismatch",
+			records: []RecordAssertion{{Topic: "bonus.events", Count: ptr(2), Timeout: 50 * time.Millisecond}},
+			wantErr: "expected 2 matching records within 50ms, got 1",
+		},
+		{
+			name:    "value mismatch lists the record",
+			records: []RecordAssertion{{Topic: "bonus.events", Timeout: 50 * time.Millisecond, Value: map[string]any{"amount": 200}}},
+			wantErr: `key="b-1"`,
+		},
+		{
+			name:    "header mismatch",
+			records: []RecordAssertion{{Topic: "bonus.events", Timeout: 50 * time.Millisecond, Headers: map[string]any{"type": "bonus.expired"}}},
+			wantErr: "header type",
+		},
+		{
+			name:    "nothing on the topic",
+			records: []RecordAssertion{{Topic: "payments.events", Timeout: 50 * time.Millisecond}},
+			wantErr: "no records received",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := kafka.NewMemory()
+			e := &Executor{Kafka: broker, Logger: utils.NewLogger()}
+			execCtx := utils.NewExecutionContext()
+			execCtx.Set("bonusId", "b-1")
+			test := DeclarativeTest{Name: tt.name, Steps: []Step{{Name: "produce", Produce: produce, Records: tt.records}}}
+
+			steps, err := e.Run(context.Background(), test, execCtx)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error %v, want it to contain %q", err, tt.wantErr)
+			}
+
+			recs := broker.Records("bonus.events")
+			if len(recs) != 1 || recs[0].Key != "b-1" || string(recs[0].Value) != `{"amount":100,"bonusId":"b-1"}` {
+				t.Fatalf("produced %+v", recs)
+			}
+			if len(steps) != 1 || steps[0].Request == nil || steps[0].Request.Method != "PRODUCE" {
+				t.Fatalf("step results %+v", steps)
+			}
+			wantStatus := result.StatusPass
+			if tt.wantErr != "" {
+				wantStatus = result.StatusFail
+			}
+			if got := steps[0].Assertions[0].Status; got != wantStatus {
+				t.Fatalf("assertion status %s, want %s", got, wantStatus)
+			}
+		})
+	}
+}
+
+func TestRecordsWithoutBroker(t *testing.T) {
+	e := &Executor{Logger: utils.NewLogger()}
+	test := DeclarativeTest{Steps: []Step{{Produce: &ProduceAction{Topic: "bonus.events"}}}}
+	steps, err := e.Run(context.Background(), test, utils.NewExecutionContext())
+	if err == nil || !strings.Contains(err.Error(), "kafka client is not configured") {
+		t.Fatalf("error %v", err)
+	}
+	if steps[0].Status != result.StatusError {
+		t.Fatalf("step status %s, want %s", steps[0].Status, result.StatusError)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,117 @@
+package declarative
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/example/go-test-framework/framework/mq/rabbitmq"
+	"github.com/example/go-test-framework/framework/result"
+	"github.com/example/go-test-framework/framework/utils"
+)
+
+func (e *Executor) rabbitClient(ctx context.Context) (rabbitmq.Broker, error) {
+	switch {
+	case e.RabbitMQ != nil:
+		return e.RabbitMQ, nil
+	case e.DB != nil:
+		return e.DB.RabbitMQ(ctx)
+	}
+	return nil, errors.New("rabbitmq client is not configured")
+}
+
+func (e *Executor) executePublish(ctx context.Context, step Step, execCtx *utils.ExecutionContext, log *utils.StructuredLogger, res *result.StepResult) error {
+	client, err := e.rabbitClient(ctx)
+	if err != nil {
+		return permanentError{err}
+	}
+	vars := execCtx.Snapshot()
+	pub := step.Publish
+	msg := rabbitmq.Message{
+		Exchange:   utils.Substitute(pub.Exchange, vars).(string),
+		RoutingKey: utils.Substitute(pub.RoutingKey, vars).(string),
+	}
+	if len(pub.Headers) > 0 {
+		msg.Headers, _ = utils.Substitute(pub.Headers, vars).(map[string]any)
+	}
+	switch body := utils.Substitute(pub.Body, vars).(type) {
+	case nil:
+	case string:
+		msg.Body = []byte(body)
+	default:
+		if msg.Body, err = json.Marshal(body); err != nil {
+			return permanentError{fmt.Errorf("encode message body: %w", err)}
+		}
+	}
+
+	res.Request = &result.HTTPRequest{Method: "PUBLISH", URL: msg.Exchange + "/" + msg.RoutingKey, Body: string(msg.Body)}
+	for k, v := range msg.Headers {
+		if res.Request.Headers == nil {
+			res.Request.Headers = map[string]string{}
+		}
+		res.Request.Headers[k] = fmt.Sprint(v)
+	}
+	if err := client.Publish(ctx, msg); err != nil {
+		return permanentError{fmt.Errorf("publish to %s: %w", res.Request.URL, err)}
+	}
+	log.Info("published message", map[string]any{"exchange": msg.Exchange, "routingKey": msg.RoutingKey})
+	return nil
+}
+
+// subscribe binds a temporary queue for every message assertion.
+func (e *Executor) subscribe(ctx context.Context, assertions []MessageAssertion, vars map[string]string) ([]*rabbitmq.Subscription, error) {
+	if len(assertions) == 0 {
+		return nil, nil
+	}
+	client, err := e.rabbitClient(ctx)
+	if err != nil {
+		return nil, permanentError{err}
+	}
+	return subscribeEach(assertions, func(a MessageAssertion) (*rabbitmq.Subscription, error) {
+		return client.Subscribe(ctx, utils.Substitute(a.Exchange, vars).(string), utils.Substitute(a.RoutingKey, vars).(string))
+	})
+}
+
+func (e *Executor) executeMessages(ctx context.Context, sub *rabbitmq.Subscription, assertion MessageAssertion, execCtx *utils.ExecutionContext) (result.AssertionResult, error) {
+	vars := execCtx.Snapshot()
+	exchange := utils.Substitute(assertion.Exchange, vars).(string)
+	routingKey := utils.Substitute(assertion.RoutingKey, vars).(string)
+	outcome := result.AssertionResult{
+		Target:   fmt.Sprintf("rabbitmq %s/%s", exchange, routingKey),
+		Status:   result.StatusPass,
+		Attempts: 1,
+		Query:    map[string]any{"exchange": exchange, "routingKey": routingKey},
+	}
+	headers, _ := utils.Substitute(assertion.Headers, vars).(map[string]any)
+	body, _ := utils.Substitute(assertion.Body, vars).(map[string]any)
+
+	err := capture[rabbitmq.Message]{
+		noun: "message",
+		buf:  &sub.Buffer,
+		mismatch: func(msg rabbitmq.Message) error {
+			return messageMismatch(msg, headers, body)
+		},
+		describe: func(msg rabbitmq.Message) string {
+			return fmt.Sprintf("%s %s", msg.RoutingKey, msg.Body)
+		},
+	}.await(ctx, assertion.Count, assertion.Timeout)
+	if err != nil {
+		outcome.Status, outcome.Diff = failureStatus(err), err.Error()
+	}
+	return outcome, err
+}
+
+// messageMismatch reports why msg does not satisfy the expected headers and
+// body paths.
+func messageMismatch(msg rabbitmq.Message, headers, body map[string]any) error {
+	if err := matchHeaders(msg.Headers, headers); err != nil {
+		return err
+	}
+	if len(body) > 0 {
+		if err := matchPaths(msg.JSON(), body); err != nil {
+			return fmt.Errorf("body: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package declarative
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	httpclient "github.com/example/go-test-framework/framework/http"
+	"github.com/example/go-test-framework/framework/mq/rabbitmq"
+	"github.com/example/go-test-framework/framework/result"
+	"github.com/example/go-test-framework/framework/utils"
+)
+
+func TestPublishAndMessages(t *testing.T) {
+	publish := &PublishAction{
+		Exchange:   "bonus.events",
+		RoutingKey: "bonus.created",
+		Headers:    map[string]any{"type": "bonus.created", "meta": map[string]any{"source": "${bonusId}"}},
+		Body:       map[string]any{"bonusId": "${bonusId}", "amount": 100},
+	}
+	tests := []struct {
+		name     string
+		messages []MessageAssertion
+		wantErr  string
+	}{
+		{
+			name:     "matching message",
+			messages: []MessageAssertion{{Exchange: "bonus.events", RoutingKey: "bonus.created", Headers: map[string]any{"type": "bonus.created"}, Body: map[string]any{"bonusId": "${bonusId}", "amount": 100}}},
+		},
+		{
+			name:     "nested header",
+			messages: []MessageAssertion{{Exchange: "bonus.events", RoutingKey: "bonus.created", Headers: map[string]any{"meta": map[string]any{"source": "b-1"}}}},
+		},
+		{
+			name:     "wildcard routing key and matcher",
+			messages: []MessageAssertion{{Exchange: "bonus.events", RoutingKey: "bonus.*", Body: map[string]any{"amount": map[string]any{"$gte": 50}}}},
+		},
+		{
+			name:     "exact count",
+			messages: []MessageAssertion{{Exchange: "bonus.events", RoutingKey: "#", Count: ptr(1), Timeout: 50 * time.Millisecond}},
+		},
+		{
+			name:     "count mismatch",
+			messages: []MessageAssertion{{Exchange: "bonus.events", RoutingKey: "#", Count: ptr(2), Timeout: 50 * time.Millisecond}},
+			wantErr:  "expected 2 matching messages within 50ms, got 1",
+		},
+		{
+			name:     "body mismatch lists the message",
+			messages: []MessageAssertion{{Exchange: "bonus.events", RoutingKey: "bonus.created", Timeout: 50 * time.Millisecond, Body: map[string]any{"amount": 200}}},
+			wantErr:  `bonus.created {"amount":100,"bonusId":"b-1"}`,
+		},
+		{
+			name:     "header mismatch",
+			messages: []MessageAssertion{{Exchange: "bonus.events", RoutingKey: "bonus.created", Timeout: 50 * time.Millisecond, Headers: map[string]any{"type": "bonus.expired"}}},
+			wantErr:  "header type",
+		},
+		{
+			name:     "routing key not bound",
+			messages: []MessageAssertion{{Exchange: "bonus.events", RoutingKey: "bonus.expired", Timeout: 50 * time.Millisecond}},
+			wantErr:  "no messages received",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := rabbitmq.NewMemory()
+			e := &Executor{RabbitMQ: broker, Logger: utils.NewLogger()}
+			execCtx := utils.NewExecutionContext()
+			execCtx.Set("bonusId", "b-1")
+			test := DeclarativeTest{Name: tt.name, Steps: []Step{{Name: "publish", Publish: publish, Messages: tt.messages}}}
+
+			steps, err := e.Run(context.Background(), test, execCtx)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error %v, want it to contain %q", err, tt.wantErr)
+			}
+
+			msgs := broker.Published("bonus.events")
+			if len(msgs) != 1 || string(msgs[0].Body) != `{"amount":100,"bonusId":"b-1"}` {
+				t.Fatalf("published %+v", msgs)
+			}
+			if len(steps) != 1 || steps[0].Request == nil || steps[0].Request.Method != "PUBLISH" {
+				t.Fatalf("step results %+v", steps)
+			}
+			wantStatus := result.StatusPass
+			if tt.wantErr != "" {
+				wantStatus = result.StatusFail
+			}
+			if got := steps[0].Assertions[0].Status; got != wantStatus {
+				t.Fatalf("assertion status %s, want %s", got, wantStatus)
+			}
+		})
+	}
+}
+
+// TestBonusCreatedMessage runs the deposit suite's bonus step against a fake
+// bonus-service that publishes bonus.created like the real one.
+func TestBonusCreatedMessage(t *testing.T) {
+	broker := rabbitmq.NewMemory()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		body, _ := json.Marshal(map[string]any{"bonusId": "b-7", "userId": req["userId"]})
+		if err := broker.Publish(r.Context(), rabbitmq.Message{Exchange: "bonus.events", RoutingKey: "bonus.created", Body: body}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": "b-7", "amount": 100, "type": "promo"}`))
+	}))
+	defer srv.Close()
+
+	e := &Executor{
+		HTTP:     httpclient.New(httpclient.StaticResolver{"bonus-service": srv.URL}),
+		RabbitMQ: broker,
+		Logger:   utils.NewLogger(),
+	}
+	test := DeclarativeTest{
+		Name: "Create bonus",
+		Action: Action{
+			Service:  "bonus-service",
+			Endpoint: "/api/bonuses/create",
+			Method:   "POST",
+			Body:     map[string]any{"userId": 1, "amount": 100, "type": "promo"},
+			Extract:  map[string]string{"bonusId": "id"},
+		},
+		ResponseAssertions: &ResponseAssertions{Status: 201},
+		Messages: []MessageAssertion{{
+			Exchange:   "bonus.events",
+			RoutingKey: "bonus.created",
+			Timeout:    time.Second,
+			Body:       map[string]any{"bonusId": "${bonusId}", "userId": 1},
+		}},
+	}
+	steps, err := e.Run(context.Background(), test, utils.NewExecutionContext())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertions := steps[0].Assertions
+	if got := assertions[len(assertions)-1]; got.Status != result.StatusPass || got.Target != "rabbitmq bonus.events/bonus.created" {
+		t.Fatalf("assertion %+v", got)
+	}
+}
+
+func TestMessagesWithoutBroker(t *testing.T) {
+	e := &Executor{Logger: utils.NewLogger()}
+	test := DeclarativeTest{Steps: []Step{{Publish: &PublishAction{Exchange: "bonus.events"}}}}
+	steps, err := e.Run(context.Background(), test, utils.NewExecutionContext())
+	if err == nil || !strings.Contains(err.Error(), "rabbitmq client is not configured") {
+		t.Fatalf("error %v", err)
+	}
+	if steps[0].Status != result.StatusError {
+		t.Fatalf("step status %s, want %s", steps[0].Status, result.StatusError)
+	}
+}
@@ -0,0 +1,62 @@
+package declarative
+
+import (
+	"context"
+	"errors"
+
+	"github.com/example/go-test-framework/framework/db/redis"
+	"github.com/example/go-test-framework/framework/utils"
+)
+
+// checkRedis runs the Redis checks of assertion with ${var} placeholders in
+// the key, pattern and expected values substituted.
+func checkRedis(ctx context.Context, client *redis.Client, assertion Assertion, vars map[string]string) error {
+	exp := assertion.Expected
+	key := utils.Substitute(assertion.Key, vars).(string)
+	if assertion.Pattern != "" {
+		if exp.Count == nil {
+			return permanentError{errors.New("redis pattern assertions need expected.count")}
+		}
+		return client.ValidateCount(ctx, utils.Substitute(assertion.Pattern, vars).(string), *exp.Count)
+	}
+	if key == "" {
+		return permanentError{errors.New("redis assertions need a key or pattern")}
+	}
+
+	if exp.Exists != nil {
+		if err := client.ValidateExists(ctx, key, *exp.Exists); err != nil {
+			return err
+		}
+	}
+	if exp.Value != nil {
+		if err := client.ValidateValue(ctx, key, utils.Substitute(exp.Value, vars)); err != nil {
+			return err
+		}
+	}
+	if len(exp.Fields) > 0 {
+		fields, _ := utils.Substitute(exp.Fields, vars).(map[string]any)
+		if err := client.ValidateFields(ctx, key, fields); err != nil {
+			return err
+		}
+	}
+	if len(exp.Members) > 0 {
+		members := make([]string, len(exp.Members))
+		for i, m := range exp.Members {
+			members[i] = utils.Substitute(m, vars).(string)
+		}
+		if err := client.ValidateMembers(ctx, key, members); err != nil {
+			return err
+		}
+	}
+	if exp.Count != nil {
+		if err := client.ValidateLen(ctx, key, *exp.Count); err != nil {
+			return err
+		}
+	}
+	if exp.TTL != nil {
+		if err := client.ValidateTTL(ctx, key, exp.TTL.Min, exp.TTL.Max); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,17 +1,88 @@
 package declarative
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/example/go-test-framework/framework/match"
+	"github.com/example/go-test-framework/framework/script"
+	"github.com/example/go-test-framework/framework/utils"
+)
 
 // DeclarativeTest models YAML/JSON driven integration flows.
 type DeclarativeTest struct {
 	Name               string              `json:"name"`
 	Description        string              `json:"description"`
+	Steps              []Step              `json:"steps"`
+	Action             Action              `json:"action"`
+	ResponseAssertions *ResponseAssertions `json:"responseAssertions"`
+	Assertions         []Assertion         `json:"assertions"`
+	Messages           []MessageAssertion  `json:"messages"`
+	Records            []RecordAssertion   `json:"records"`
+	DelayAfter         time.Duration       `json:"delayAfter"`
+}
+
+// Step is a single action of a scenario with its own checks.
+type Step struct {
+	Name               string              `json:"name"`
 	Action             Action              `json:"action"`
+	Script             *script.Spec        `json:"script"`
+	Publish            *PublishAction      `json:"publish"`
+	Produce            *ProduceAction      `json:"produce"`
 	ResponseAssertions *ResponseAssertions `json:"responseAssertions"`
 	Assertions         []Assertion         `json:"assertions"`
+	Messages           []MessageAssertion  `json:"messages"`
+	Records            []RecordAssertion   `json:"records"`
 	DelayAfter         time.Duration       `json:"delayAfter"`
 }
 
+// ExecutionSteps returns Steps, or the top-level fields as a single step. It
+// errors when both are set.
+func (t DeclarativeTest) ExecutionSteps() ([]Step, error) {
+	if len(t.Steps) > 0 {
+		if fields := t.topLevelFields(); len(fields) > 0 {
+			return nil, fmt.Errorf("steps cannot be combined with top-level %s", strings.Join(fields, ", "))
+		}
+		return t.Steps, nil
+	}
+	return []Step{{
+		Name:               t.Name,
+		Action:             t.Action,
+		ResponseAssertions: t.ResponseAssertions,
+		Assertions:         t.Assertions,
+		Messages:           t.Messages,
+		Records:            t.Records,
+		DelayAfter:         t.DelayAfter,
+	}}, nil
+}
+
+// topLevelFields names the single-step fields set on t.
+func (t DeclarativeTest) topLevelFields() []string {
+	var fields []string
+	a := t.Action
+	if a.Service != "" || a.Endpoint != "" || a.Method != "" || a.Body != nil || len(a.Headers) > 0 || len(a.Extract) > 0 {
+		fields = append(fields, "action")
+	}
+	if t.ResponseAssertions != nil {
+		fields = append(fields, "responseAssertions")
+	}
+	if len(t.Assertions) > 0 {
+		fields = append(fields, "assertions")
+	}
+	if len(t.Messages) > 0 {
+		fields = append(fields, "messages")
+	}
+	if len(t.Records) > 0 {
+		fields = append(fields, "records")
+	}
+	if t.DelayAfter != 0 {
+		fields = append(fields, "delayAfter")
+	}
+	return fields
+}
+
 // Action describes the HTTP call to perform.
 type Action struct {
 	Service  string            `json:"service"`
@@ -22,6 +93,42 @@ type Action struct {
 	Extract  map[string]string `json:"extract"`
 }
 
+// PublishAction sends a message to a RabbitMQ exchange.
+type PublishAction struct {
+	Exchange   string         `json:"exchange"`
+	RoutingKey string         `json:"routingKey"`
+	Headers    map[string]any `json:"headers"`
+	Body       any            `json:"body"`
+}
+
+// MessageAssertion expects messages on a RabbitMQ exchange.
+type MessageAssertion struct {
+	Exchange   string         `json:"exchange"`
+	RoutingKey string         `json:"routingKey"`
+	Timeout    time.Duration  `json:"timeout"`
+	Count      *int           `json:"count"`
+	Headers    map[string]any `json:"headers"`
+	Body       map[string]any `json:"body"`
+}
+
+// ProduceAction writes a record to a Kafka topic.
+type ProduceAction struct {
+	Topic   string            `json:"topic"`
+	Key     string            `json:"key"`
+	Headers map[string]string `json:"headers"`
+	Value   any               `json:"value"`
+}
+
+// RecordAssertion expects records on a Kafka topic.
+type RecordAssertion struct {
+	Topic   string         `json:"topic"`
+	Timeout time.Duration  `json:"timeout"`
+	Count   *int           `json:"count"`
+	Key     any            `json:"key"`
+	Headers map[string]any `json:"headers"`
+	Value   map[string]any `json:"value"`
+}
+
 // ResponseAssertions holds HTTP validations.
 type ResponseAssertions struct {
 	Status int             `json:"status"`
@@ -35,16 +142,63 @@ type BodyAssertions struct {
 
 // Assertion defines a DB validation.
 type Assertion struct {
-	Database     string         `json:"database"`
-	DatabaseName string         `json:"databaseName"`
-	Collection   string         `json:"collection"`
-	Schema       string         `json:"schema"`
-	Table        string         `json:"table"`
-	Query        map[string]any `json:"query"`
-	Expected     ExpectedResult `json:"expected"`
+	Database     string            `json:"database"`
+	DatabaseName string            `json:"databaseName"`
+	Collection   string            `json:"collection"`
+	Schema       string            `json:"schema"`
+	Table        string            `json:"table"`
+	Key          string            `json:"key"`
+	Pattern      string            `json:"pattern"`
+	Query        map[string]any    `json:"query"`
+	SQL          string            `json:"sql"`
+	Pipeline     []json.RawMessage `json:"pipeline"`
+	OrderBy      []string          `json:"orderBy"`
+	Limit        int               `json:"limit"`
+	Expected     ExpectedResult    `json:"expected"`
+	Eventually   *Eventually       `json:"eventually"`
 }
 
+// Eventually re-runs an assertion until it holds or Timeout expires.
+type Eventually struct {
+	Timeout  time.Duration `json:"timeout"`
+	Interval time.Duration `json:"interval"`
+	Backoff  float64       `json:"backoff"`
+}
+
+// ExpectedResult lists the checks of an assertion.
 type ExpectedResult struct {
-	Count    *int           `json:"count"`
-	Contains map[string]any `json:"contains"`
+	Count    *int             `json:"count"`
+	Contains map[string]any   `json:"contains"`
+	Match    string           `json:"match"`
+	Rows     []map[string]any `json:"rows"`
+	Ordered  bool             `json:"ordered"`
+	Exists   *bool            `json:"exists"`
+	Value    any              `json:"value"`
+	Fields   map[string]any   `json:"fields"`
+	Members  []string         `json:"members"`
+	TTL      *TTLRange        `json:"ttl"`
+}
+
+// RowSpec returns the row expectations with ${var} placeholders substituted,
+// and false when the assertion has none.
+func (e ExpectedResult) RowSpec(vars map[string]string) (match.RowSpec, bool) {
+	if e.Match == "" && len(e.Contains) == 0 && e.Rows == nil {
+		return match.RowSpec{}, false
+	}
+	spec := match.RowSpec{Mode: e.Match, Ordered: e.Ordered}
+	spec.Contains, _ = utils.Substitute(e.Contains, vars).(map[string]any)
+	for _, row := range e.Rows {
+		substituted, _ := utils.Substitute(row, vars).(map[string]any)
+		spec.Rows = append(spec.Rows, substituted)
+	}
+	if spec.Rows == nil && e.Rows != nil {
+		spec.Rows = []map[string]any{}
+	}
+	return spec, true
+}
+
+// TTLRange bounds the remaining time to live of a key.
+type TTLRange struct {
+	Min time.Duration `json:"min"`
+	Max time.Duration `json:"max"`
 }
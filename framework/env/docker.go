@@ -0,0 +1,281 @@
+package env
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Docker starts every declared dependency as a local container through the
+// docker CLI, publishes its port on 127.0.0.1, waits until it answers and
+// creates the declared databases and users. Containers are named
+// gtf-<suite>-<dependency> and removed by Release.
+type Docker struct {
+	// Binary defaults to "docker"; any CLI compatible with `docker run`,
+	// `exec`, `port` and `rm` (podman, nerdctl) works.
+	Binary       string
+	ReadyTimeout time.Duration
+
+	mu         sync.Mutex
+	containers map[string][]string
+}
+
+func (d *Docker) Provision(ctx context.Context, suiteID string, cfg EnvironmentConfig) (endpoints *Endpoints, err error) {
+	endpoints = &Endpoints{}
+	defer func() {
+		if err != nil {
+			_ = d.Release(context.WithoutCancel(ctx), suiteID)
+		}
+	}()
+
+	if pg := cfg.Postgres; pg != nil {
+		if endpoints.Postgres, err = d.startPostgres(ctx, suiteID, pg); err != nil {
+			return nil, err
+		}
+	}
+	if mg := cfg.Mongo; mg != nil {
+		if endpoints.Mongo, err = d.startMongo(ctx, suiteID, mg); err != nil {
+			return nil, err
+		}
+	}
+	if rd := cfg.Redis; rd != nil {
+		name := containerName(suiteID, "redis")
+		addr, err := d.start(ctx, suiteID, name, "redis:"+imageTag(rd.Version), rd.Memory, "6379", nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := d.waitExec(ctx, name, "redis-cli", "ping"); err != nil {
+			return nil, err
+		}
+		endpoints.Redis = addr
+	}
+	if mq := cfg.RabbitMQ; mq != nil {
+		name := containerName(suiteID, "rabbitmq")
+		addr, err := d.start(ctx, suiteID, name, "rabbitmq:"+imageTag(mq.Version), mq.Memory, "5672", nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := d.waitExec(ctx, name, "rabbitmq-diagnostics", "-q", "check_port_connectivity"); err != nil {
+			return nil, err
+		}
+		endpoints.RabbitMQ = rabbitURL(addr, "guest", "guest")
+	}
+	return endpoints, nil
+}
+
+func (d *Docker) startPostgres(ctx context.Context, suiteID string, cfg *PostgresConfig) ([]Database, error) {
+	name := containerName(suiteID, "postgres")
+	password := randomPassword()
+	addr, err := d.start(ctx, suiteID, name, "postgres:"+imageTag(cfg.Version), cfg.Memory, "5432", map[string]string{"POSTGRES_PASSWORD": password})
+	if err != nil {
+		return nil, err
+	}
+	// The image restarts the server after initdb; only the final server
+	// listens on TCP, so check over TCP rather than the unix socket.
+	if err := d.waitExec(ctx, name, "pg_isready", "-h", "127.0.0.1", "-U", "postgres"); err != nil {
+		return nil, err
+	}
+	if len(cfg.Databases) == 0 {
+		return []Database{{Name: "postgres", URL: postgresURL(addr, "postgres", password, "postgres")}}, nil
+	}
+
+	var dbs []Database
+	users := map[string]bool{}
+	for _, db := range cfg.Databases {
+		user := pgx.Identifier{db.Username}.Sanitize()
+		var stmts []string
+		// Databases may share an owner; the role can only be created once.
+		if !users[db.Username] {
+			users[db.Username] = true
+			stmts = append(stmts, fmt.Sprintf("CREATE USER %s WITH PASSWORD %s", user, quoteLiteral(db.Password)))
+		}
+		stmts = append(stmts, fmt.Sprintf("CREATE DATABASE %s OWNER %s", pgx.Identifier{db.Name}.Sanitize(), user))
+		for _, stmt := range stmts {
+			if _, err := d.exec(ctx, name, "psql", "-h", "127.0.0.1", "-U", "postgres", "-v", "ON_ERROR_STOP=1", "-c", stmt); err != nil {
+				return nil, fmt.Errorf("postgres database %s: %w", db.Name, err)
+			}
+		}
+		dbs = append(dbs, Database{Name: db.Name, URL: postgresURL(addr, db.Username, db.Password, db.Name)})
+	}
+	return dbs, nil
+}
+
+func (d *Docker) startMongo(ctx context.Context, suiteID string, cfg *MongoConfig) ([]Database, error) {
+	name := containerName(suiteID, "mongodb")
+	password := randomPassword()
+	addr, err := d.start(ctx, suiteID, name, "mongo:"+imageTag(cfg.Version), cfg.Memory, "27017", map[string]string{
+		"MONGO_INITDB_ROOT_USERNAME": "root",
+		"MONGO_INITDB_ROOT_PASSWORD": password,
+	})
+	if err != nil {
+		return nil, err
+	}
+	mongosh := []string{"mongosh", "--quiet", "--host", "127.0.0.1", "-u", "root", "-p", password, "--authenticationDatabase", "admin", "--eval"}
+	if err := d.waitExec(ctx, name, append(mongosh, "db.adminCommand('ping')")...); err != nil {
+		return nil, err
+	}
+	if len(cfg.Databases) == 0 {
+		return []Database{{Name: "admin", URL: mongoURL(addr, "root", password, "admin")}}, nil
+	}
+
+	var dbs []Database
+	for _, db := range cfg.Databases {
+		user, _ := json.Marshal(map[string]any{
+			"user":  db.Username,
+			"pwd":   db.Password,
+			"roles": []map[string]string{{"role": "dbOwner", "db": db.Name}},
+		})
+		dbName, _ := json.Marshal(db.Name)
+		username, _ := json.Marshal(db.Username)
+		// The image restarts mongod after its init phase, so the script is
+		// idempotent and retried until the final server accepts it.
+		script := fmt.Sprintf("const d = db.getSiblingDB(%s); if (!d.getUser(%s)) d.createUser(%s)", dbName, username, user)
+		if err := d.waitExec(ctx, name, append(mongosh, script)...); err != nil {
+			return nil, fmt.Errorf("mongodb database %s: %w", db.Name, err)
+		}
+		dbs = append(dbs, Database{Name: db.Name, URL: mongoURL(addr, db.Username, db.Password, db.Name)})
+	}
+	return dbs, nil
+}
+
+// start runs image detached and returns the host address of port.
+func (d *Docker) start(ctx context.Context, suiteID, name, image, memory, port string, env map[string]string) (string, error) {
+	args := []string{"run", "-d", "--name", name, "-p", "127.0.0.1::" + port}
+	if memory != "" {
+		args = append(args, "--memory", dockerMemory(memory))
+	}
+	for _, k := range sortedKeys(env) {
+		args = append(args, "-e", k+"="+env[k])
+	}
+	args = append(args, image)
+
+	// Remove leftovers of an interrupted run so the name is free.
+	_, _ = d.run(ctx, "rm", "-f", name)
+	if _, err := d.run(ctx, args...); err != nil {
+		return "", fmt.Errorf("start %s: %w", image, err)
+	}
+	d.mu.Lock()
+	if d.containers == nil {
+		d.containers = map[string][]string{}
+	}
+	d.containers[suiteID] = append(d.containers[suiteID], name)
+	d.mu.Unlock()
+
+	out, err := d.run(ctx, "port", name, port+"/tcp")
+	if err != nil {
+		return "", fmt.Errorf("port of %s: %w", name, err)
+	}
+	addr, _, _ := strings.Cut(strings.TrimSpace(out), "\n")
+	host, hostPort, err := net.SplitHostPort(strings.TrimSpace(addr))
+	if err != nil {
+		return "", fmt.Errorf("port of %s: unexpected output %q", name, out)
+	}
+	if host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	addr = net.JoinHostPort(host, hostPort)
+	if err := waitReady(ctx, name, d.readyTimeout(), dialCheck(addr)); err != nil {
+		return "", err
+	}
+	return addr, nil
+}
+
+// Release removes the containers started for suiteID.
+func (d *Docker) Release(ctx context.Context, suiteID string) error {
+	d.mu.Lock()
+	names := d.containers[suiteID]
+	delete(d.containers, suiteID)
+	d.mu.Unlock()
+	if len(names) == 0 {
+		return nil
+	}
+	_, err := d.run(ctx, append([]string{"rm", "-f", "-v"}, names...)...)
+	return err
+}
+
+func (d *Docker) waitExec(ctx context.Context, container string, cmd ...string) error {
+	return waitReady(ctx, container, d.readyTimeout(), func(ctx context.Context) error {
+		_, err := d.exec(ctx, container, cmd...)
+		return err
+	})
+}
+
+func (d *Docker) exec(ctx context.Context, container string, cmd ...string) (string, error) {
+	return d.run(ctx, append([]string{"exec", container}, cmd...)...)
+}
+
+func (d *Docker) run(ctx context.Context, args ...string) (string, error) {
+	binary := d.Binary
+	if binary == "" {
+		binary = "docker"
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return stdout.String(), fmt.Errorf("%s %s: %w: %s", binary, args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+func (d *Docker) readyTimeout() time.Duration {
+	if d.ReadyTimeout > 0 {
+		return d.ReadyTimeout
+	}
+	return 2 * time.Minute
+}
+
+var unsafeName = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+func containerName(suiteID, dependency string) string {
+	return "gtf-" + unsafeName.ReplaceAllString(suiteID, "-") + "-" + dependency
+}
+
+func imageTag(version string) string {
+	if version == "" {
+		return "latest"
+	}
+	return version
+}
+
+// dockerMemory converts Kubernetes quantities (512Mi, 1Gi) to docker's
+// --memory syntax (512m, 1g).
+func dockerMemory(q string) string {
+	for suffix, unit := range map[string]string{"Ki": "k", "Mi": "m", "Gi": "g"} {
+		if strings.HasSuffix(q, suffix) {
+			return strings.TrimSuffix(q, suffix) + unit
+		}
+	}
+	return q
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func randomPassword() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,100 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Existing points suites at dependencies that are already running, for
+// example a shared staging cluster. The declared databases and users are
+// expected to exist; Provision only builds their URLs and checks that every
+// requested address accepts connections.
+type Existing struct {
+	PostgresAddr string
+	MongoAddr    string
+	RedisAddr    string
+	RabbitMQAddr string
+	// MongoUser and MongoPassword authenticate the admin connection of
+	// suites that declare no Mongo databases, such as the root user of a
+	// rendered environment.
+	MongoUser     string
+	MongoPassword string
+	// RabbitMQUser and RabbitMQPassword default to guest/guest.
+	RabbitMQUser     string
+	RabbitMQPassword string
+	ReadyTimeout     time.Duration
+}
+
+func (e *Existing) Provision(ctx context.Context, _ string, cfg EnvironmentConfig) (*Endpoints, error) {
+	timeout := e.ReadyTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	endpoints := &Endpoints{}
+	check := func(what, addr string) error {
+		if addr == "" {
+			return fmt.Errorf("suite needs %s but no address is configured", what)
+		}
+		return waitReady(ctx, what+" at "+addr, timeout, dialCheck(addr))
+	}
+
+	if cfg.Postgres != nil {
+		if err := check("postgres", e.PostgresAddr); err != nil {
+			return nil, err
+		}
+		for _, db := range cfg.Postgres.Databases {
+			endpoints.Postgres = append(endpoints.Postgres, Database{Name: db.Name, URL: postgresURL(e.PostgresAddr, db.Username, db.Password, db.Name)})
+		}
+	}
+	if cfg.Mongo != nil {
+		if err := check("mongodb", e.MongoAddr); err != nil {
+			return nil, err
+		}
+		for _, db := range cfg.Mongo.Databases {
+			endpoints.Mongo = append(endpoints.Mongo, Database{Name: db.Name, URL: mongoURL(e.MongoAddr, db.Username, db.Password, db.Name)})
+		}
+		if len(endpoints.Mongo) == 0 {
+			endpoints.Mongo = []Database{{Name: "admin", URL: mongoURL(e.MongoAddr, e.MongoUser, e.MongoPassword, "admin")}}
+		}
+	}
+	if cfg.Redis != nil {
+		if err := check("redis", e.RedisAddr); err != nil {
+			return nil, err
+		}
+		endpoints.Redis = e.RedisAddr
+	}
+	if cfg.RabbitMQ != nil {
+		if err := check("rabbitmq", e.RabbitMQAddr); err != nil {
+			return nil, err
+		}
+		user, password := e.RabbitMQUser, e.RabbitMQPassword
+		if user == "" {
+			user, password = "guest", "guest"
+		}
+		endpoints.RabbitMQ = rabbitURL(e.RabbitMQAddr, user, password)
+	}
+	return endpoints, nil
+}
+
+// Release is a no-op: existing dependencies outlive the run.
+func (e *Existing) Release(context.Context, string) error { return nil }
+
+func postgresURL(addr, user, password, database string) string {
+	u := url.URL{Scheme: "postgres", User: url.UserPassword(user, password), Host: addr, Path: "/" + database, RawQuery: "sslmode=disable"}
+	return u.String()
+}
+
+func mongoURL(addr, user, password, database string) string {
+	u := url.URL{Scheme: "mongodb", Host: addr, Path: "/" + database}
+	if user != "" {
+		u.User = url.UserPassword(user, password)
+		u.RawQuery = "authSource=" + url.QueryEscape(database)
+	}
+	return u.String()
+}
+
+func rabbitURL(addr, user, password string) string {
+	return fmt.Sprintf("amqp://%s@%s/", url.UserPassword(user, password).String(), addr)
+}
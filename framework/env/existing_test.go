@@ -0,0 +1,40 @@
+package env
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestExistingMongoAdminURL(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	addr := ln.Addr().String()
+
+	tests := []struct {
+		name     string
+		existing Existing
+		cfg      MongoConfig
+		want     string
+	}{
+		{name: "anonymous admin", existing: Existing{MongoAddr: addr}, want: "mongodb://" + addr + "/admin"},
+		{name: "root of a rendered environment", existing: Existing{MongoAddr: addr, MongoUser: "root", MongoPassword: RootPassword("deposit")}, want: "mongodb://root:" + RootPassword("deposit") + "@" + addr + "/admin?authSource=admin"},
+		{name: "declared database", existing: Existing{MongoAddr: addr, MongoUser: "root", MongoPassword: "x"}, cfg: MongoConfig{Databases: []MongoDBEntry{{Name: "payments", Username: "svc", Password: "p"}}}, want: "mongodb://svc:p@" + addr + "/payments?authSource=payments"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.existing.ReadyTimeout = time.Second
+			endpoints, err := tt.existing.Provision(context.Background(), "deposit", EnvironmentConfig{Mongo: &tt.cfg})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(endpoints.Mongo) != 1 || endpoints.Mongo[0].URL != tt.want {
+				t.Fatalf("mongo endpoints %+v, want %s", endpoints.Mongo, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Provisioner makes the dependencies of an EnvironmentConfig available for a
+// suite and reports where they can be reached. Release frees whatever
+// Provision set up for the same suite id.
+type Provisioner interface {
+	Provision(ctx context.Context, suiteID string, cfg EnvironmentConfig) (*Endpoints, error)
+	Release(ctx context.Context, suiteID string) error
+}
+
+// Endpoints is the connection info of provisioned dependencies. Databases are
+// listed in the order they were declared; fields of dependencies that were
+// not requested stay empty.
+type Endpoints struct {
+	Postgres []Database `json:"postgres,omitempty"`
+	Mongo    []Database `json:"mongodb,omitempty"`
+	Redis    string     `json:"redis,omitempty"`
+	RabbitMQ string     `json:"rabbitmq,omitempty"`
+}
+
+// Database is a connection URL for one declared database.
+type Database struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// PostgresURL returns the DSN of database name, or of the first declared
+// database when name is empty.
+func (e *Endpoints) PostgresURL(name string) (string, bool) {
+	return lookupDatabase(e.Postgres, name)
+}
+
+// MongoURL returns the URI of database name, or of the first declared
+// database when name is empty.
+func (e *Endpoints) MongoURL(name string) (string, bool) {
+	return lookupDatabase(e.Mongo, name)
+}
+
+func lookupDatabase(dbs []Database, name string) (string, bool) {
+	for _, db := range dbs {
+		if name == "" || db.Name == name {
+			return db.URL, true
+		}
+	}
+	return "", false
+}
+
+// IsEmpty reports whether cfg declares no dependencies.
+func (cfg EnvironmentConfig) IsEmpty() bool {
+	return cfg.Postgres == nil && cfg.Mongo == nil && cfg.Redis == nil && cfg.RabbitMQ == nil
+}
+
+// waitReady calls check until it succeeds, ctx is done or timeout passes.
+func waitReady(ctx context.Context, what string, timeout time.Duration, check func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		err := check(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s not ready after %s: %w", what, timeout, err)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+func dialCheck(addr string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// Env exposes the endpoints to scripts as POSTGRES_URL, MONGO_URL,
+// REDIS_ADDR and RABBITMQ_URL; the URLs point at the first declared
+// database.
+func (e *Endpoints) Env() map[string]string {
+	vars := map[string]string{}
+	if dsn, ok := e.PostgresURL(""); ok {
+		vars["POSTGRES_URL"] = dsn
+	}
+	if uri, ok := e.MongoURL(""); ok {
+		vars["MONGO_URL"] = uri
+	}
+	if e.Redis != "" {
+		vars["REDIS_ADDR"] = e.Redis
+	}
+	if e.RabbitMQ != "" {
+		vars["RABBITMQ_URL"] = e.RabbitMQ
+	}
+	return vars
+}
@@ -0,0 +1,279 @@
+package env
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// dependency is the deployment-neutral description of one declared service
+// shared by the Kubernetes and compose renderers.
+type dependency struct {
+	Name      string
+	Image     string
+	Port      int
+	Memory    string
+	Env       map[string]string
+	InitDir   string
+	InitFiles map[string]string
+	Probe     []string
+}
+
+// RootPassword is the default password of the postgres superuser and the
+// mongodb root user in rendered environments. It is derived from the suite id
+// so repeated renders produce the same output; it is not a secret.
+func RootPassword(suiteID string) string {
+	sum := sha256.Sum256([]byte("go-test-framework root " + suiteID))
+	return hex.EncodeToString(sum[:12])
+}
+
+func dependencies(cfg EnvironmentConfig, rootPassword string) []dependency {
+	var deps []dependency
+	if pg := cfg.Postgres; pg != nil {
+		dep := dependency{
+			Name:   "postgres",
+			Image:  "postgres:" + imageTag(pg.Version),
+			Port:   5432,
+			Memory: pg.Memory,
+			Env:    map[string]string{"POSTGRES_PASSWORD": rootPassword},
+			Probe:  []string{"pg_isready", "-U", "postgres"},
+		}
+		if len(pg.Databases) > 0 {
+			dep.InitDir = "/docker-entrypoint-initdb.d"
+			dep.InitFiles = map[string]string{"01-databases.sql": postgresInitSQL(pg.Databases)}
+		}
+		deps = append(deps, dep)
+	}
+	if mg := cfg.Mongo; mg != nil {
+		dep := dependency{
+			Name:   "mongodb",
+			Image:  "mongo:" + imageTag(mg.Version),
+			Port:   27017,
+			Memory: mg.Memory,
+			Env: map[string]string{
+				"MONGO_INITDB_ROOT_USERNAME": "root",
+				"MONGO_INITDB_ROOT_PASSWORD": rootPassword,
+			},
+			Probe: []string{"mongosh", "--quiet", "--eval", "db.adminCommand('ping')"},
+		}
+		if len(mg.Databases) > 0 {
+			dep.InitDir = "/docker-entrypoint-initdb.d"
+			dep.InitFiles = map[string]string{"01-users.js": mongoInitJS(mg.Databases)}
+		}
+		deps = append(deps, dep)
+	}
+	if rd := cfg.Redis; rd != nil {
+		deps = append(deps, dependency{
+			Name:   "redis",
+			Image:  "redis:" + imageTag(rd.Version),
+			Port:   6379,
+			Memory: rd.Memory,
+			Probe:  []string{"redis-cli", "ping"},
+		})
+	}
+	if mq := cfg.RabbitMQ; mq != nil {
+		deps = append(deps, dependency{
+			Name:   "rabbitmq",
+			Image:  "rabbitmq:" + imageTag(mq.Version),
+			Port:   5672,
+			Memory: mq.Memory,
+			Probe:  []string{"rabbitmq-diagnostics", "-q", "ping"},
+		})
+	}
+	return deps
+}
+
+func postgresInitSQL(dbs []PostgresDBEntry) string {
+	var b strings.Builder
+	users := map[string]bool{}
+	for _, db := range dbs {
+		user := pgx.Identifier{db.Username}.Sanitize()
+		if !users[db.Username] {
+			users[db.Username] = true
+			fmt.Fprintf(&b, "CREATE USER %s WITH PASSWORD %s;\n", user, quoteLiteral(db.Password))
+		}
+		fmt.Fprintf(&b, "CREATE DATABASE %s OWNER %s;\n", pgx.Identifier{db.Name}.Sanitize(), user)
+	}
+	return b.String()
+}
+
+func mongoInitJS(dbs []MongoDBEntry) string {
+	var b strings.Builder
+	for _, db := range dbs {
+		name, _ := json.Marshal(db.Name)
+		user, _ := json.Marshal(map[string]any{
+			"user":  db.Username,
+			"pwd":   db.Password,
+			"roles": []map[string]string{{"role": "dbOwner", "db": db.Name}},
+		})
+		fmt.Fprintf(&b, "db.getSiblingDB(%s).createUser(%s);\n", name, user)
+	}
+	return b.String()
+}
+
+// RenderKubernetes returns a multi-document manifest with a Secret,
+// Deployment and Service per declared dependency of the suite. Init scripts
+// creating the declared databases and users are mounted from a second
+// Secret because they contain passwords. An empty rootPassword means
+// RootPassword(suiteID).
+func RenderKubernetes(suiteID string, cfg EnvironmentConfig, rootPassword string) ([]byte, error) {
+	var docs []any
+	for _, dep := range dependencies(cfg, defaultRootPassword(suiteID, rootPassword)) {
+		name := resourceName(suiteID, dep.Name)
+		labels := map[string]string{
+			"app.kubernetes.io/name":     dep.Name,
+			"app.kubernetes.io/instance": name,
+			"app.kubernetes.io/part-of":  resourceName(suiteID, ""),
+		}
+		container := map[string]any{
+			"name":  dep.Name,
+			"image": dep.Image,
+			"ports": []map[string]any{{"containerPort": dep.Port}},
+			"readinessProbe": map[string]any{
+				"exec":          map[string]any{"command": dep.Probe},
+				"periodSeconds": 5,
+			},
+		}
+		if dep.Memory != "" {
+			container["resources"] = map[string]any{
+				"requests": map[string]string{"memory": dep.Memory},
+				"limits":   map[string]string{"memory": dep.Memory},
+			}
+		}
+		pod := map[string]any{"containers": []any{container}}
+
+		if len(dep.Env) > 0 {
+			docs = append(docs, secret(name, labels, dep.Env))
+			container["envFrom"] = []any{map[string]any{"secretRef": map[string]string{"name": name}}}
+		}
+		if len(dep.InitFiles) > 0 {
+			docs = append(docs, secret(name+"-init", labels, dep.InitFiles))
+			container["volumeMounts"] = []any{map[string]any{"name": "init", "mountPath": dep.InitDir, "readOnly": true}}
+			pod["volumes"] = []any{map[string]any{"name": "init", "secret": map[string]string{"secretName": name + "-init"}}}
+		}
+
+		docs = append(docs, map[string]any{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]any{"name": name, "labels": labels},
+			"spec": map[string]any{
+				"replicas": 1,
+				"selector": map[string]any{"matchLabels": map[string]string{"app.kubernetes.io/instance": name}},
+				"template": map[string]any{
+					"metadata": map[string]any{"labels": labels},
+					"spec":     pod,
+				},
+			},
+		}, map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata":   map[string]any{"name": name, "labels": labels},
+			"spec": map[string]any{
+				"selector": map[string]string{"app.kubernetes.io/instance": name},
+				"ports":    []map[string]any{{"name": dep.Name, "port": dep.Port, "targetPort": dep.Port}},
+			},
+		})
+	}
+	return encodeYAML(docs...)
+}
+
+func secret(name string, labels, data map[string]string) map[string]any {
+	return map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]any{"name": name, "labels": labels},
+		"type":       "Opaque",
+		"stringData": data,
+	}
+}
+
+// RenderCompose returns a docker-compose file with one service per declared
+// dependency, published on its standard port so `-provision existing` works
+// against it unchanged. Init scripts are inlined as compose configs. An empty
+// rootPassword means RootPassword(suiteID).
+func RenderCompose(suiteID string, cfg EnvironmentConfig, rootPassword string) ([]byte, error) {
+	services := map[string]any{}
+	configs := map[string]any{}
+	for _, dep := range dependencies(cfg, defaultRootPassword(suiteID, rootPassword)) {
+		port := strconv.Itoa(dep.Port)
+		svc := map[string]any{
+			"image": dep.Image,
+			"ports": []string{port + ":" + port},
+			"healthcheck": map[string]any{
+				"test":     append([]string{"CMD"}, dep.Probe...),
+				"interval": "5s",
+				"retries":  30,
+			},
+		}
+		if len(dep.Env) > 0 {
+			svc["environment"] = dep.Env
+		}
+		if dep.Memory != "" {
+			svc["mem_limit"] = dockerMemory(dep.Memory)
+		}
+		var mounts []any
+		for _, file := range sortedKeys(dep.InitFiles) {
+			key := dep.Name + "-" + strings.TrimSuffix(file, filepath.Ext(file))
+			configs[key] = map[string]string{"content": dep.InitFiles[file]}
+			mounts = append(mounts, map[string]string{"source": key, "target": dep.InitDir + "/" + file})
+		}
+		if len(mounts) > 0 {
+			svc["configs"] = mounts
+		}
+		services[dep.Name] = svc
+	}
+	doc := map[string]any{"name": resourceName(suiteID, ""), "services": services}
+	if len(configs) > 0 {
+		doc["configs"] = configs
+	}
+	return encodeYAML(doc)
+}
+
+func defaultRootPassword(suiteID, rootPassword string) string {
+	if rootPassword == "" {
+		return RootPassword(suiteID)
+	}
+	return rootPassword
+}
+
+func encodeYAML(docs ...any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var invalidResourceChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// resourceName builds a DNS-1123 label from the suite id and dependency.
+func resourceName(suiteID, dependency string) string {
+	name := strings.Trim(invalidResourceChars.ReplaceAllString(strings.ToLower(suiteID), "-"), "-")
+	limit := 63
+	if dependency != "" {
+		limit -= len(dependency) + 1
+	}
+	if len(name) > limit {
+		name = strings.TrimRight(name[:limit], "-")
+	}
+	if dependency == "" {
+		return name
+	}
+	return name + "-" + dependency
+}
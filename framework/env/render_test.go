@@ -0,0 +1,73 @@
+package env
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var renderConfig = EnvironmentConfig{
+	Postgres: &PostgresConfig{Version: "15", Databases: []PostgresDBEntry{
+		{Name: "payments", Username: "svc", Password: "p1"},
+		{Name: "admin_db", Username: "svc", Password: "p1"},
+	}},
+	Mongo: &MongoConfig{Version: "7", Databases: []MongoDBEntry{{Name: "payments", Username: "svc", Password: "p2"}}},
+	Redis: &RedisConfig{Version: "7"},
+}
+
+func TestRenderIsDeterministic(t *testing.T) {
+	renderers := map[string]func(string, EnvironmentConfig, string) ([]byte, error){
+		"k8s":     RenderKubernetes,
+		"compose": RenderCompose,
+	}
+	for name, render := range renderers {
+		t.Run(name, func(t *testing.T) {
+			first, err := render("deposit", renderConfig, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := render("deposit", renderConfig, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(first, second) {
+				t.Fatalf("renders differ:\n%s\n---\n%s", first, second)
+			}
+			out := string(first)
+			if strings.Count(out, RootPassword("deposit")) != 2 {
+				t.Fatalf("default root password not used for postgres and mongodb:\n%s", out)
+			}
+
+			custom, err := render("deposit", renderConfig, "s3cret")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Count(string(custom), "s3cret") != 2 || strings.Contains(string(custom), RootPassword("deposit")) {
+				t.Fatalf("root password override not applied:\n%s", custom)
+			}
+		})
+	}
+}
+
+func TestRootPassword(t *testing.T) {
+	if RootPassword("deposit") != RootPassword("deposit") {
+		t.Fatal("RootPassword is not stable")
+	}
+	if RootPassword("deposit") == RootPassword("bonus") {
+		t.Fatal("suites share a root password")
+	}
+	if len(RootPassword("deposit")) != 24 {
+		t.Fatalf("RootPassword = %q", RootPassword("deposit"))
+	}
+}
+
+func TestPostgresInitSQL(t *testing.T) {
+	got := postgresInitSQL(renderConfig.Postgres.Databases)
+	want := `CREATE USER "svc" WITH PASSWORD 'p1';
+CREATE DATABASE "payments" OWNER "svc";
+CREATE DATABASE "admin_db" OWNER "svc";
+`
+	if got != want {
+		t.Fatalf("init SQL\n%s\nwant\n%s", got, want)
+	}
+}
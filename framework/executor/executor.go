@@ -1,35 +1,141 @@
 package executor
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/example/go-test-framework/framework/result"
 	"github.com/example/go-test-framework/framework/suite"
 	"github.com/example/go-test-framework/framework/utils"
 )
 
-// TestExecutor is a placeholder for future service-specific logic. For now it
-// logs that a test would run.
+// maxFailureOutput bounds how much output of a single failing test is copied
+// into the returned error; the full output stays on the step result.
+const maxFailureOutput = 4096
+
+// waitDelay bounds how long an interrupted run waits for test binaries that
+// outlive the go command and keep its output open.
+const waitDelay = 2 * time.Second
+
+// TestExecutor runs `go test -json` for a service found under WorkDir and
+// reports one step per Go test. Packages and Tags are defaults that a
+// TestDefinition can override.
 type TestExecutor struct {
-	Logger *utils.StructuredLogger
+	WorkDir  string
+	GoBinary string
+	Packages []string
+	Tags     []string
+	Logger   *utils.StructuredLogger
+}
+
+func (te *TestExecutor) Run(ctx context.Context, _ *suite.TestSuite, definition suite.TestDefinition, _ *utils.ExecutionContext) ([]result.StepResult, error) {
+	log := utils.LoggerFromContext(ctx, te.Logger)
+	dir := filepath.Join(te.WorkDir, definition.Service)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("service directory %s not found", dir)
+	}
+
+	args := te.args(definition)
+	log.Info("running go test", map[string]any{"service": definition.Service, "type": definition.Type, "dir": dir, "args": args})
+
+	goBinary := te.GoBinary
+	if goBinary == "" {
+		goBinary = "go"
+	}
+	cmd := exec.CommandContext(ctx, goBinary, args...)
+	cmd.Dir = dir
+	cmd.WaitDelay = waitDelay
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	report, err := parseGoTestJSON(&stdout)
+	if ctx.Err() != nil {
+		// Keep what ran before the deadline: unfinished tests show up as
+		// failed steps with their output, which points at the one that hung.
+		var steps []result.StepResult
+		if err == nil {
+			steps = report.Steps()
+		}
+		return steps, goTestInterrupted(definition.Service, failedSteps(steps), ctx.Err())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading go test output: %w", err)
+	}
+	steps := report.Steps()
+	failed := failedSteps(steps)
+	log.Info("finished go test", map[string]any{"service": definition.Service, "tests": len(steps), "failed": len(failed)})
+
+	if len(failed) > 0 {
+		return steps, goTestFailure(definition.Service, failed)
+	}
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(runErr, &exitErr) {
+			return steps, fmt.Errorf("running go test for %s: %w", definition.Service, runErr)
+		}
+		output := strings.TrimSpace(stderr.String() + report.stray.String())
+		return steps, fmt.Errorf("go test for %s exited with code %d:\n%s", definition.Service, exitErr.ExitCode(), output)
+	}
+	return steps, nil
+}
+
+func (te *TestExecutor) args(definition suite.TestDefinition) []string {
+	args := []string{"test", "-json"}
+	tags := te.Tags
+	if len(definition.Tags) > 0 {
+		tags = definition.Tags
+	}
+	if len(tags) > 0 {
+		args = append(args, "-tags", strings.Join(tags, ","))
+	}
+	if definition.Run != "" {
+		args = append(args, "-run", definition.Run)
+	}
+	packages := te.Packages
+	if len(definition.Packages) > 0 {
+		packages = definition.Packages
+	}
+	if len(packages) == 0 {
+		packages = []string{"./..."}
+	}
+	return append(args, packages...)
+}
+
+func goTestFailure(service string, failed []result.StepResult) error {
+	names := make([]string, len(failed))
+	var details strings.Builder
+	for i, step := range failed {
+		names[i] = step.Name
+		output := step.Failure
+		if len(output) > maxFailureOutput {
+			output = output[len(output)-maxFailureOutput:]
+		}
+		fmt.Fprintf(&details, "\n--- %s\n%s", step.Name, output)
+	}
+	return fmt.Errorf("go test failed for %s: %s%s", service, strings.Join(names, ", "), details.String())
 }
 
-func (te *TestExecutor) Run(ctx context.Context, definition suite.TestDefinition) error {
-	if te.Logger == nil {
-		te.Logger = utils.NewLogger()
+func goTestInterrupted(service string, failed []result.StepResult, err error) error {
+	if len(failed) == 0 {
+		return fmt.Errorf("go test for %s: %w", service, err)
 	}
-	te.Logger.Info("running test", map[string]any{"service": definition.Service, "type": definition.Type})
-	select {
-	case <-time.After(100 * time.Millisecond):
-	case <-ctx.Done():
-		return ctx.Err()
+	names := make([]string, len(failed))
+	for i, step := range failed {
+		names[i] = step.Name
 	}
-	te.Logger.Info("finished test", map[string]any{"service": definition.Service})
-	return nil
+	return fmt.Errorf("go test for %s: %w; unfinished or failed: %s", service, err, strings.Join(names, ", "))
 }
 
-// BuildExecutor is a helper that could wire dependencies based on suite config.
-func BuildExecutor() *TestExecutor {
-	return &TestExecutor{Logger: utils.NewLogger()}
+// BuildExecutor returns a TestExecutor for services under workDir.
+func BuildExecutor(workDir string) *TestExecutor {
+	return &TestExecutor{WorkDir: workDir, Logger: utils.NewLogger()}
 }
@@ -0,0 +1,116 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/go-test-framework/framework/result"
+	"github.com/example/go-test-framework/framework/suite"
+)
+
+// fakeGo writes a shell script that stands in for the go binary and prints
+// output as `go test -json` lines before running tail.
+func fakeGo(t *testing.T, output, tail string) (workDir, goBinary string) {
+	t.Helper()
+	workDir = t.TempDir()
+	if err := os.Mkdir(filepath.Join(workDir, "svc"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	goBinary = filepath.Join(t.TempDir(), "go")
+	script := "#!/bin/sh\ncat <<'JSON'\n" + output + "JSON\n" + tail + "\n"
+	if err := os.WriteFile(goBinary, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return workDir, goBinary
+}
+
+func TestRunKeepsOutputOnTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		tail string
+	}{
+		{name: "go command hangs", tail: "exec sleep 10"},
+		// The child keeps stdout open after the go command is killed, like a
+		// test binary does.
+		{name: "test binary outlives go", tail: "sleep 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRunKeepsOutputOnTimeout(t, tt.tail)
+		})
+	}
+}
+
+func testRunKeepsOutputOnTimeout(t *testing.T, tail string) {
+	workDir, goBinary := fakeGo(t, `{"Action":"run","Package":"svc/a","Test":"TestFast"}
+{"Action":"pass","Package":"svc/a","Test":"TestFast","Elapsed":0.01}
+{"Action":"run","Package":"svc/a","Test":"TestHangs"}
+{"Action":"output","Package":"svc/a","Test":"TestHangs","Output":"waiting for wallet\n"}
+`, tail)
+	te := &TestExecutor{WorkDir: workDir, GoBinary: goBinary}
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	steps, err := te.Run(ctx, nil, suite.TestDefinition{Service: "svc"}, nil)
+	if elapsed := time.Since(started); elapsed > waitDelay+time.Second {
+		t.Fatalf("Run took %s after the deadline", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error %v, want a deadline error", err)
+	}
+	if !strings.Contains(err.Error(), "unfinished or failed: TestHangs (svc/a)") {
+		t.Fatalf("error %q does not name the hanging test", err)
+	}
+	if len(steps) != 2 {
+		t.Fatalf("steps %+v", steps)
+	}
+	if steps[0].Name != "TestFast (svc/a)" || steps[0].Status != result.StatusPass {
+		t.Errorf("step 0 = %s %s", steps[0].Name, steps[0].Status)
+	}
+	if steps[1].Name != "TestHangs (svc/a)" || steps[1].Status != result.StatusFail || steps[1].Failure != "waiting for wallet" {
+		t.Errorf("step 1 = %s %s %q", steps[1].Name, steps[1].Status, steps[1].Failure)
+	}
+}
+
+func TestRunReportsFailedTests(t *testing.T) {
+	workDir, goBinary := fakeGo(t, `{"Action":"run","Package":"svc/a","Test":"TestBad"}
+{"Action":"output","Package":"svc/a","Test":"TestBad","Output":"boom\n"}
+{"Action":"fail","Package":"svc/a","Test":"TestBad"}
+{"Action":"fail","Package":"svc/a"}
+`, "exit 1")
+	te := &TestExecutor{WorkDir: workDir, GoBinary: goBinary}
+	steps, err := te.Run(context.Background(), nil, suite.TestDefinition{Service: "svc"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "go test failed for svc: TestBad (svc/a)\n--- TestBad (svc/a)\nboom") {
+		t.Fatalf("error %v", err)
+	}
+	if len(steps) != 1 || steps[0].Status != result.StatusFail {
+		t.Fatalf("steps %+v", steps)
+	}
+}
+
+func TestArgs(t *testing.T) {
+	te := &TestExecutor{Packages: []string{"./internal/..."}, Tags: []string{"integration"}}
+	tests := []struct {
+		name string
+		te   *TestExecutor
+		def  suite.TestDefinition
+		want string
+	}{
+		{name: "no defaults", te: &TestExecutor{}, want: "test -json ./..."},
+		{name: "executor defaults", te: te, want: "test -json -tags integration ./internal/..."},
+		{name: "definition overrides", te: te, def: suite.TestDefinition{Packages: []string{"./a", "./b"}, Tags: []string{"e2e", "slow"}, Run: "TestX"}, want: "test -json -tags e2e,slow -run TestX ./a ./b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(tt.te.args(tt.def), " "); got != tt.want {
+				t.Fatalf("args %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
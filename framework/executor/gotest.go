@@ -0,0 +1,132 @@
+package executor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/example/go-test-framework/framework/result"
+)
+
+// testEvent is a single line of `go test -json` (test2json) output.
+type testEvent struct {
+	Time       time.Time `json:"Time"`
+	Action     string    `json:"Action"`
+	Package    string    `json:"Package"`
+	ImportPath string    `json:"ImportPath"`
+	Test       string    `json:"Test"`
+	Elapsed    float64   `json:"Elapsed"`
+	Output     string    `json:"Output"`
+}
+
+// goTestReport accumulates per-test results from a `go test -json` stream.
+// Package-level failures without a failing test (build errors, panics in
+// TestMain) are kept as results named after the package.
+type goTestReport struct {
+	order   []string
+	results map[string]*goTestResult
+	stray   strings.Builder
+}
+
+type goTestResult struct {
+	Package string
+	Test    string
+	Status  result.Status
+	Elapsed time.Duration
+	Output  strings.Builder
+}
+
+func parseGoTestJSON(r io.Reader) (*goTestReport, error) {
+	report := &goTestReport{results: map[string]*goTestResult{}}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var ev testEvent
+		if len(line) == 0 || line[0] != '{' || json.Unmarshal(line, &ev) != nil {
+			report.stray.Write(line)
+			report.stray.WriteByte('\n')
+			continue
+		}
+		report.add(ev)
+	}
+	return report, scanner.Err()
+}
+
+func (g *goTestReport) add(ev testEvent) {
+	pkg := ev.Package
+	if pkg == "" {
+		// Build events use import paths such as "svc/b [svc/b.test]".
+		pkg, _, _ = strings.Cut(ev.ImportPath, " ")
+	}
+	key := pkg + " " + ev.Test
+	res, ok := g.results[key]
+	if !ok {
+		res = &goTestResult{Package: pkg, Test: ev.Test}
+		g.results[key] = res
+		g.order = append(g.order, key)
+	}
+	switch ev.Action {
+	case "output", "build-output":
+		res.Output.WriteString(ev.Output)
+	case "pass":
+		res.Status = result.StatusPass
+	case "fail", "build-fail":
+		res.Status = result.StatusFail
+	case "skip":
+		res.Status = result.StatusSkip
+	}
+	if ev.Elapsed > 0 {
+		res.Elapsed = time.Duration(ev.Elapsed * float64(time.Second))
+	}
+}
+
+// Steps converts the report into step results: every test, plus packages
+// that failed on their own.
+func (g *goTestReport) Steps() []result.StepResult {
+	var steps []result.StepResult
+	for _, key := range g.order {
+		res := g.results[key]
+		if res.Test == "" && (res.Status != result.StatusFail || g.hasFailedTest(res.Package)) {
+			continue
+		}
+		name := res.Package
+		if res.Test != "" {
+			name = fmt.Sprintf("%s (%s)", res.Test, res.Package)
+		}
+		status := res.Status
+		if status == "" {
+			// Tests without a final action were interrupted by a panic or timeout.
+			status = result.StatusFail
+		}
+		step := result.StepResult{Name: name, Status: status, Duration: res.Elapsed}
+		if status == result.StatusFail {
+			step.Failure = strings.TrimRight(res.Output.String(), "\n")
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+func (g *goTestReport) hasFailedTest(pkg string) bool {
+	for _, res := range g.results {
+		if res.Package == pkg && res.Test != "" && (res.Status == result.StatusFail || res.Status == "") {
+			return true
+		}
+	}
+	return false
+}
+
+// failedSteps returns the steps that failed.
+func failedSteps(steps []result.StepResult) []result.StepResult {
+	var failed []result.StepResult
+	for _, step := range steps {
+		if step.Status == result.StatusFail {
+			failed = append(failed, step)
+		}
+	}
+	return failed
+}
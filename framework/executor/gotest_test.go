@@ -0,0 +1,155 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/go-test-framework/framework/result"
+)
+
+func TestParseGoTestJSON(t *testing.T) {
+	type step struct {
+		name    string
+		status  result.Status
+		failure string
+	}
+	tests := []struct {
+		name      string
+		input     string
+		want      []step
+		wantStray string
+	}{
+		{
+			name: "build failure",
+			input: `{"ImportPath":"svc/b [svc/b.test]","Action":"build-output","Output":"# svc/b [svc/b.test]\n"}
+{"ImportPath":"svc/b [svc/b.test]","Action":"build-output","Output":"b/b.go:2:23: cannot use \"x\" (untyped string constant) as int value in return statement\n"}
+{"ImportPath":"svc/b [svc/b.test]","Action":"build-fail"}
+{"Action":"start","Package":"svc/b"}
+{"Action":"output","Package":"svc/b","Output":"FAIL\tsvc/b [build failed]\n","OutputType":"frame"}
+{"Action":"fail","Package":"svc/b","Elapsed":0,"FailedBuild":"svc/b [svc/b.test]"}
+`,
+			want: []step{{
+				name:    "svc/b",
+				status:  result.StatusFail,
+				failure: "# svc/b [svc/b.test]\nb/b.go:2:23: cannot use \"x\" (untyped string constant) as int value in return statement\nFAIL\tsvc/b [build failed]",
+			}},
+		},
+		{
+			name: "build failure next to a passing package",
+			input: `{"ImportPath":"svc/b [svc/b.test]","Action":"build-output","Output":"b/b.go:2:23: undefined: G\n"}
+{"ImportPath":"svc/b [svc/b.test]","Action":"build-fail"}
+{"Action":"start","Package":"svc/a"}
+{"Action":"run","Package":"svc/a","Test":"TestOK"}
+{"Action":"output","Package":"svc/a","Test":"TestOK","Output":"=== RUN   TestOK\n"}
+{"Action":"pass","Package":"svc/a","Test":"TestOK","Elapsed":0.25}
+{"Action":"pass","Package":"svc/a","Elapsed":0.3}
+{"Action":"start","Package":"svc/b"}
+{"Action":"fail","Package":"svc/b","Elapsed":0,"FailedBuild":"svc/b [svc/b.test]"}
+`,
+			want: []step{
+				{name: "svc/b", status: result.StatusFail, failure: "b/b.go:2:23: undefined: G"},
+				{name: "TestOK (svc/a)", status: result.StatusPass},
+			},
+		},
+		{
+			name: "failing, passing and skipped tests",
+			input: `{"Action":"start","Package":"svc/a"}
+{"Action":"run","Package":"svc/a","Test":"TestOK"}
+{"Action":"pass","Package":"svc/a","Test":"TestOK","Elapsed":0}
+{"Action":"run","Package":"svc/a","Test":"TestBad"}
+{"Action":"output","Package":"svc/a","Test":"TestBad","Output":"=== RUN   TestBad\n"}
+{"Action":"output","Package":"svc/a","Test":"TestBad","Output":"    a_test.go:4: boom\n"}
+{"Action":"output","Package":"svc/a","Test":"TestBad","Output":"--- FAIL: TestBad (0.00s)\n"}
+{"Action":"fail","Package":"svc/a","Test":"TestBad","Elapsed":0}
+{"Action":"run","Package":"svc/a","Test":"TestSkip"}
+{"Action":"skip","Package":"svc/a","Test":"TestSkip","Elapsed":0}
+{"Action":"output","Package":"svc/a","Output":"FAIL\tsvc/a\t0.003s\n"}
+{"Action":"fail","Package":"svc/a","Elapsed":0.005}
+`,
+			want: []step{
+				{name: "TestOK (svc/a)", status: result.StatusPass},
+				{name: "TestBad (svc/a)", status: result.StatusFail, failure: "=== RUN   TestBad\n    a_test.go:4: boom\n--- FAIL: TestBad (0.00s)"},
+				{name: "TestSkip (svc/a)", status: result.StatusSkip},
+			},
+		},
+		{
+			name: "test interrupted by a panic",
+			input: `{"Action":"run","Package":"svc/a","Test":"TestPanic"}
+{"Action":"output","Package":"svc/a","Test":"TestPanic","Output":"panic: nil map\n"}
+{"Action":"output","Package":"svc/a","Output":"FAIL\tsvc/a\t0.010s\n"}
+{"Action":"fail","Package":"svc/a","Elapsed":0.01}
+`,
+			want: []step{{name: "TestPanic (svc/a)", status: result.StatusFail, failure: "panic: nil map"}},
+		},
+		{
+			name: "package failing without tests",
+			input: `{"Action":"start","Package":"svc/c"}
+{"Action":"output","Package":"svc/c","Output":"TestMain: database unavailable\n"}
+{"Action":"fail","Package":"svc/c","Elapsed":0.1}
+`,
+			want: []step{{name: "svc/c", status: result.StatusFail, failure: "TestMain: database unavailable"}},
+		},
+		{
+			name: "passing package without tests is not a step",
+			input: `{"Action":"start","Package":"svc/d"}
+{"Action":"output","Package":"svc/d","Output":"?   \tsvc/d\t[no test files]\n"}
+{"Action":"skip","Package":"svc/d","Elapsed":0}
+`,
+		},
+		{
+			name: "lines that are not events",
+			input: `go: downloading example.com/mod v1.0.0
+{"Action":"run","Package":"svc/a","Test":"TestOK"}
+{"Action":"pass","Package":"svc/a","Test":"TestOK","Elapsed":0}
+{not json
+`,
+			want:      []step{{name: "TestOK (svc/a)", status: result.StatusPass}},
+			wantStray: "go: downloading example.com/mod v1.0.0\n{not json\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := parseGoTestJSON(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			steps := report.Steps()
+			if len(steps) != len(tt.want) {
+				t.Fatalf("got %d steps %+v, want %d", len(steps), steps, len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := steps[i]
+				if got.Name != want.name || got.Status != want.status || got.Failure != want.failure {
+					t.Errorf("step %d = {%q %s %q}, want {%q %s %q}", i, got.Name, got.Status, got.Failure, want.name, want.status, want.failure)
+				}
+			}
+			if got := report.stray.String(); got != tt.wantStray {
+				t.Errorf("stray output %q, want %q", got, tt.wantStray)
+			}
+		})
+	}
+}
+
+func TestParseGoTestJSONElapsed(t *testing.T) {
+	report, err := parseGoTestJSON(strings.NewReader(`{"Action":"pass","Package":"svc/a","Test":"TestSlow","Elapsed":1.5}` + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := report.Steps()[0].Duration; d != 1500*time.Millisecond {
+		t.Fatalf("duration %s, want 1.5s", d)
+	}
+}
+
+func TestFailedSteps(t *testing.T) {
+	steps := []result.StepResult{
+		{Name: "a", Status: result.StatusPass},
+		{Name: "b", Status: result.StatusFail},
+		{Name: "c", Status: result.StatusSkip},
+		{Name: "d", Status: result.StatusFail},
+	}
+	failed := failedSteps(steps)
+	if len(failed) != 2 || failed[0].Name != "b" || failed[1].Name != "d" {
+		t.Fatalf("failedSteps = %+v", failed)
+	}
+}
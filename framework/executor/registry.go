@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/example/go-test-framework/framework/result"
+	"github.com/example/go-test-framework/framework/suite"
+	"github.com/example/go-test-framework/framework/utils"
+)
+
+// Executor runs TestDefinitions of one type. The suite and the shared
+// execution context are passed along so executors can read suite Config and
+// exchange variables with declarative tests.
+type Executor interface {
+	Run(ctx context.Context, ts *suite.TestSuite, def suite.TestDefinition, execCtx *utils.ExecutionContext) ([]result.StepResult, error)
+}
+
+// ExecutorFunc adapts a function to the Executor interface.
+type ExecutorFunc func(ctx context.Context, ts *suite.TestSuite, def suite.TestDefinition, execCtx *utils.ExecutionContext) ([]result.StepResult, error)
+
+func (f ExecutorFunc) Run(ctx context.Context, ts *suite.TestSuite, def suite.TestDefinition, execCtx *utils.ExecutionContext) ([]result.StepResult, error) {
+	return f(ctx, ts, def, execCtx)
+}
+
+// Registry maps TestDefinition.Type values (case-insensitive) to executors so
+// teams can plug in handlers such as k6, newman or in-house runners.
+type Registry struct {
+	mu        sync.RWMutex
+	executors map[string]Executor
+}
+
+func NewRegistry() *Registry {
+	return &Registry{executors: map[string]Executor{}}
+}
+
+// Register installs ex for testType, replacing any previous handler.
+func (r *Registry) Register(testType string, ex Executor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.executors[normalizeType(testType)] = ex
+}
+
+// Lookup returns the executor for testType or an error naming the
+// registered types.
+func (r *Registry) Lookup(testType string) (Executor, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if ex, ok := r.executors[normalizeType(testType)]; ok {
+		return ex, nil
+	}
+	return nil, fmt.Errorf("no executor registered for test type %q (registered: %s)", testType, strings.Join(r.typesLocked(), ", "))
+}
+
+// Types lists the registered test types in sorted order.
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.typesLocked()
+}
+
+func (r *Registry) typesLocked() []string {
+	types := make([]string, 0, len(r.executors))
+	for t := range r.executors {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func normalizeType(testType string) string {
+	return strings.ToLower(strings.TrimSpace(testType))
+}
+
+// BuildRegistry returns a registry with the built-in executors: `go test`
+// for the "integration" and "unit" types and commands for "script".
+func BuildRegistry(workDir string) *Registry {
+	reg := NewRegistry()
+	goTest := BuildExecutor(workDir)
+	reg.Register("integration", goTest)
+	reg.Register("unit", goTest)
+	reg.Register("script", &ScriptExecutor{WorkDir: workDir, Logger: goTest.Logger})
+	return reg
+}
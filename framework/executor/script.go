@@ -0,0 +1,79 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/example/go-test-framework/framework/result"
+	"github.com/example/go-test-framework/framework/script"
+	"github.com/example/go-test-framework/framework/suite"
+	"github.com/example/go-test-framework/framework/utils"
+)
+
+// ScriptExecutor runs TestDefinition.Script inside work/<service> (or
+// WorkDir when no service is set). The environment attached to ctx (suite
+// Config values and provisioned endpoints, see runner.RunSuite) and the
+// execution context variables are exported as environment variables, and
+// extracted values are written back into the execution context.
+type ScriptExecutor struct {
+	WorkDir string
+	Logger  *utils.StructuredLogger
+}
+
+func (se *ScriptExecutor) Run(ctx context.Context, ts *suite.TestSuite, def suite.TestDefinition, execCtx *utils.ExecutionContext) ([]result.StepResult, error) {
+	if def.Script == nil {
+		return nil, errors.New("script test definition needs a script spec")
+	}
+	log := utils.LoggerFromContext(ctx, se.Logger)
+	dir := se.WorkDir
+	if def.Service != "" {
+		dir = filepath.Join(se.WorkDir, def.Service)
+	}
+
+	var vars map[string]string
+	if execCtx != nil {
+		vars = execCtx.Snapshot()
+	}
+	out, err := script.Run(ctx, *def.Script, dir, script.Env(ctx, vars))
+	step := ScriptStep(out)
+	if err != nil {
+		step.Status, step.Failure = result.StatusError, err.Error()
+		return []result.StepResult{step}, err
+	}
+	err = def.Script.Check(out)
+	var extracted map[string]string
+	if err == nil {
+		extracted, err = def.Script.ExtractVars(out)
+	}
+	if err != nil {
+		step.Status, step.Failure = result.StatusFail, err.Error()
+		return []result.StepResult{step}, err
+	}
+	for k, v := range extracted {
+		if execCtx != nil {
+			execCtx.Set(k, v)
+		}
+		log.Info("extracted variable", map[string]any{"key": k, "value": v})
+	}
+	step.Status = result.StatusPass
+	return []result.StepResult{step}, nil
+}
+
+// ScriptStep converts command output into a step result without a status.
+func ScriptStep(out *script.Output) result.StepResult {
+	if out == nil {
+		return result.StepResult{Name: "script"}
+	}
+	return result.StepResult{
+		Name:     strings.SplitN(out.Command, "\n", 2)[0],
+		Duration: out.Duration,
+		Command: &result.CommandRun{
+			Command:  out.Command,
+			ExitCode: out.ExitCode,
+			Stdout:   out.Stdout,
+			Stderr:   out.Stderr,
+		},
+	}
+}
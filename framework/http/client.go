@@ -49,13 +49,24 @@ type Request struct {
 	Headers  map[string]string
 }
 
-// Response is a normalized HTTP response.
+// Response is a normalized HTTP response. URL is the resolved request URL.
 type Response struct {
+	URL        string
 	StatusCode int
 	Body       map[string]any
 	Raw        []byte
 }
 
+// JSON decodes the raw body, which may be any JSON value such as a top-level
+// array. Bodies that are not JSON are returned as Body, i.e. under "_raw".
+func (r *Response) JSON() any {
+	var v any
+	if err := json.Unmarshal(r.Raw, &v); err != nil {
+		return r.Body
+	}
+	return v
+}
+
 func (c *Client) Do(ctx context.Context, req Request) (*Response, error) {
 	base, err := c.resolver.Resolve(req.Service)
 	if err != nil {
@@ -101,5 +112,5 @@ func (c *Client) Do(ctx context.Context, req Request) (*Response, error) {
 		}
 	}
 
-	return &Response{StatusCode: resp.StatusCode, Body: body, Raw: raw}, nil
+	return &Response{URL: url, StatusCode: resp.StatusCode, Body: body, Raw: raw}, nil
 }
@@ -0,0 +1,273 @@
+package jsonpath
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Path is a compiled path expression. Both JSONPath (`$.data.items[0].id`)
+// and dotted gjson-style paths (`data.items.0.id`) are accepted, along with
+// wildcards (`items[*]`, `data.*`), recursive descent (`$..id`) and filters
+// (`items[?(@.status == 'active')]`).
+type Path struct {
+	expr     string
+	segments []segment
+}
+
+type segmentKind int
+
+const (
+	segmentKey segmentKind = iota
+	segmentIndex
+	segmentWildcard
+	segmentRecursive
+	segmentFilter
+)
+
+type segment struct {
+	kind   segmentKind
+	key    string
+	index  int
+	filter *filter
+}
+
+// Compile parses expr into a reusable Path.
+func Compile(expr string) (*Path, error) {
+	p := &parser{src: strings.TrimSpace(expr)}
+	segments, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", expr, err)
+	}
+	return &Path{expr: expr, segments: segments}, nil
+}
+
+func (p *Path) String() string {
+	return p.expr
+}
+
+// Definite reports whether the path can select at most one value, i.e. it
+// contains no wildcards, recursive descent or filters.
+func (p *Path) Definite() bool {
+	for _, seg := range p.segments {
+		switch seg.kind {
+		case segmentWildcard, segmentRecursive, segmentFilter:
+			return false
+		}
+	}
+	return true
+}
+
+// Find returns every value selected by the path in document order.
+func (p *Path) Find(doc any) []any {
+	current := []any{doc}
+	for _, seg := range p.segments {
+		var next []any
+		for _, node := range current {
+			next = append(next, seg.apply(node)...)
+		}
+		current = next
+		if len(current) == 0 {
+			break
+		}
+	}
+	return current
+}
+
+// Get resolves the path against doc. Definite paths return the single value
+// they point at; other paths return the list of matches. An error describing
+// where resolution stopped is returned when nothing matches.
+func (p *Path) Get(doc any) (any, error) {
+	if !p.Definite() {
+		matches := p.Find(doc)
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("path %q matched nothing", p.expr)
+		}
+		return matches, nil
+	}
+	node := doc
+	walked := "$"
+	for _, seg := range p.segments {
+		value, err := seg.step(node, walked)
+		if err != nil {
+			return nil, fmt.Errorf("path %q did not resolve: %w", p.expr, err)
+		}
+		node = value
+		walked += seg.String()
+	}
+	return node, nil
+}
+
+// Get compiles expr and resolves it against doc.
+func Get(doc any, expr string) (any, error) {
+	p, err := Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return p.Get(doc)
+}
+
+func (s segment) String() string {
+	switch s.kind {
+	case segmentKey:
+		return "." + s.key
+	case segmentIndex:
+		return fmt.Sprintf("[%d]", s.index)
+	case segmentWildcard:
+		return "[*]"
+	case segmentRecursive:
+		return ".."
+	default:
+		return "[?()]"
+	}
+}
+
+// step resolves a definite segment and explains why it failed.
+func (s segment) step(node any, at string) (any, error) {
+	switch s.kind {
+	case segmentKey:
+		switch v := node.(type) {
+		case map[string]any:
+			value, ok := v[s.key]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found at %s", s.key, at)
+			}
+			return value, nil
+		case []any:
+			if idx, err := strconv.Atoi(s.key); err == nil {
+				return indexSegment(idx).step(node, at)
+			}
+		}
+		if mapped, ok := asMap(node); ok {
+			return s.step(mapped, at)
+		}
+		return nil, fmt.Errorf("cannot read key %q from %s at %s", s.key, typeName(node), at)
+	case segmentIndex:
+		list, ok := asList(node)
+		if !ok {
+			return nil, fmt.Errorf("cannot index %s at %s", typeName(node), at)
+		}
+		idx := s.index
+		if idx < 0 {
+			idx += len(list)
+		}
+		if idx < 0 || idx >= len(list) {
+			return nil, fmt.Errorf("index %d out of range (len %d) at %s", s.index, len(list), at)
+		}
+		return list[idx], nil
+	}
+	return nil, fmt.Errorf("segment %s is not definite", s)
+}
+
+func indexSegment(idx int) segment {
+	return segment{kind: segmentIndex, index: idx}
+}
+
+func (s segment) apply(node any) []any {
+	switch s.kind {
+	case segmentKey, segmentIndex:
+		value, err := s.step(node, "")
+		if err != nil {
+			return nil
+		}
+		return []any{value}
+	case segmentWildcard:
+		return children(node)
+	case segmentRecursive:
+		return descendants(node)
+	case segmentFilter:
+		var out []any
+		for _, child := range children(node) {
+			if s.filter.match(child) {
+				out = append(out, child)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// children lists array elements in order or object values sorted by key so
+// wildcard results are deterministic.
+func children(node any) []any {
+	if list, ok := asList(node); ok {
+		return list
+	}
+	m, ok := asMap(node)
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]any, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, m[k])
+	}
+	return out
+}
+
+// descendants returns node itself followed by every nested value, which is
+// what the segment after `..` is applied to.
+func descendants(node any) []any {
+	out := []any{node}
+	for _, child := range children(node) {
+		out = append(out, descendants(child)...)
+	}
+	return out
+}
+
+func asMap(node any) (map[string]any, bool) {
+	if m, ok := node.(map[string]any); ok {
+		return m, true
+	}
+	rv := reflect.ValueOf(node)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	out := make(map[string]any, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		out[iter.Key().String()] = iter.Value().Interface()
+	}
+	return out, true
+}
+
+func asList(node any) ([]any, bool) {
+	if list, ok := node.([]any); ok {
+		return list, true
+	}
+	rv := reflect.ValueOf(node)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	out := make([]any, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out, true
+}
+
+func typeName(node any) string {
+	switch node.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case float64, float32, int, int64, int32:
+		return "number"
+	}
+	if _, ok := asList(node); ok {
+		return "array"
+	}
+	if _, ok := asMap(node); ok {
+		return "object"
+	}
+	return fmt.Sprintf("%T", node)
+}
@@ -0,0 +1,165 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const doc = `{
+	"data": {
+		"bonus": {"id": "b-1", "amount": 100},
+		"items": [
+			{"id": 1, "status": "active", "amount": 10, "tags": ["new"]},
+			{"id": 2, "status": "expired", "amount": 25},
+			{"id": 3, "status": "active", "amount": 40, "code": "PROMO-7"}
+		]
+	},
+	"list": [[1, 2], [3, 4]],
+	"empty": []
+}`
+
+func decode(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestGet(t *testing.T) {
+	root := decode(t, doc)
+	tests := []struct {
+		name string
+		expr string
+		want any
+	}{
+		{name: "jsonpath key", expr: "$.data.bonus.id", want: "b-1"},
+		{name: "dotted key", expr: "data.bonus.amount", want: float64(100)},
+		{name: "bracket key", expr: "$['data']['bonus'][\"id\"]", want: "b-1"},
+		{name: "index", expr: "$.data.items[1].id", want: float64(2)},
+		{name: "dotted index", expr: "data.items.2.id", want: float64(3)},
+		{name: "nested index", expr: "$.list[1][0]", want: float64(3)},
+		{name: "negative index", expr: "$.data.items[-1].id", want: float64(3)},
+		{name: "negative index from start", expr: "$.data.items[-3].id", want: float64(1)},
+		{name: "negative nested index", expr: "$.list[-1][-1]", want: float64(4)},
+		{name: "wildcard", expr: "$.data.items[*].id", want: []any{float64(1), float64(2), float64(3)}},
+		{name: "object wildcard", expr: "$.data.bonus.*", want: []any{float64(100), "b-1"}},
+		{name: "recursive descent", expr: "$..code", want: []any{"PROMO-7"}},
+		{name: "filter equals", expr: "$.data.items[?(@.status == 'active')].id", want: []any{float64(1), float64(3)}},
+		{name: "filter double quotes", expr: `$.data.items[?(@.status == "expired")].id`, want: []any{float64(2)}},
+		{name: "filter not equals", expr: "$.data.items[?(@.status != 'active')].id", want: []any{float64(2)}},
+		{name: "filter greater than", expr: "$.data.items[?(@.amount > 10)].id", want: []any{float64(2), float64(3)}},
+		{name: "filter less or equal", expr: "$.data.items[?(@.amount <= 25)].id", want: []any{float64(1), float64(2)}},
+		{name: "filter and", expr: "$.data.items[?(@.status == 'active' && @.amount >= 20)].id", want: []any{float64(3)}},
+		{name: "filter or", expr: "$.data.items[?(@.id == 1 || @.id == 2)].status", want: []any{"active", "expired"}},
+		{name: "filter exists", expr: "$.data.items[?(@.code)].id", want: []any{float64(3)}},
+		{name: "filter missing field not equal", expr: "$.data.items[?(@.code != 'PROMO-7')].id", want: []any{float64(1), float64(2)}},
+		{name: "filter regex", expr: "$.data.items[?(@.code =~ '^PROMO-[0-9]+$')].id", want: []any{float64(3)}},
+		{name: "filter nested path", expr: "$.data.items[?(@.tags[0] == 'new')].id", want: []any{float64(1)}},
+		{name: "filter string with bracket", expr: "$.data.items[?(@.status == 'a]b')].id", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Get(root, tt.expr)
+			if tt.want == nil {
+				if err == nil {
+					t.Fatalf("Get(%s) = %v, want no match", tt.expr, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get(%s): %v", tt.expr, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Get(%s) = %#v, want %#v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTopLevelArray(t *testing.T) {
+	root := decode(t, `[{"id": "a"}, {"id": "b"}]`)
+	tests := []struct {
+		expr string
+		want any
+	}{
+		{expr: "[0].id", want: "a"},
+		{expr: "$[1].id", want: "b"},
+		{expr: "$[-1].id", want: "b"},
+		{expr: "0.id", want: "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := Get(root, tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("Get(%s) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetErrors(t *testing.T) {
+	root := decode(t, doc)
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr string
+	}{
+		{name: "missing key", expr: "$.data.bonus.code", wantErr: `key "code" not found at $.data.bonus`},
+		{name: "index out of range", expr: "$.data.items[3]", wantErr: "index 3 out of range (len 3)"},
+		{name: "negative index out of range", expr: "$.data.items[-4]", wantErr: "index -4 out of range (len 3)"},
+		{name: "index into empty list", expr: "$.empty[-1]", wantErr: "index -1 out of range (len 0)"},
+		{name: "index an object", expr: "$.data.bonus[0]", wantErr: "cannot index object"},
+		{name: "key of a string", expr: "$.data.bonus.id.value", wantErr: `cannot read key "value" from string`},
+		{name: "filter matches nothing", expr: "$.data.items[?(@.status == 'pending')]", wantErr: "matched nothing"},
+		{name: "empty expression", expr: "", wantErr: "empty expression"},
+		{name: "unterminated bracket", expr: "$.data.items[0", wantErr: "unterminated '['"},
+		{name: "invalid index", expr: "$.data.items[x]", wantErr: `invalid index "x"`},
+		{name: "filter without parentheses", expr: "$.data.items[?@.id == 1]", wantErr: "filter must be wrapped in ?( )"},
+		{name: "filter term without @", expr: "$.data.items[?(id == 1)]", wantErr: "must start with @"},
+		{name: "filter bad literal", expr: "$.data.items[?(@.id == one)]", wantErr: "invalid literal one"},
+		{name: "filter bad regex", expr: "$.data.items[?(@.code =~ '(')]", wantErr: "error parsing regexp"},
+		{name: "current node outside filter", expr: "@.id", wantErr: "only valid inside filters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Get(root, tt.expr)
+			if err == nil {
+				t.Fatalf("Get(%s) = %v, want error", tt.expr, got)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Get(%s) error %q, want it to contain %q", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefinite(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{expr: "$.data.items[0].id", want: true},
+		{expr: "$.data.items[-1]", want: true},
+		{expr: "$.data.items[*].id", want: false},
+		{expr: "$..id", want: false},
+		{expr: "$.data.items[?(@.id == 1)]", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			p, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.Definite(); got != tt.want {
+				t.Fatalf("Definite() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,378 @@
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) parse() ([]segment, error) {
+	if p.src == "" {
+		return nil, errors.New("empty expression")
+	}
+	if p.src[0] == '$' {
+		p.pos++
+	} else if p.src[0] == '@' {
+		return nil, errors.New("'@' is only valid inside filters")
+	}
+
+	var segments []segment
+	first := p.pos == 0
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == '.' && strings.HasPrefix(p.src[p.pos:], ".."):
+			p.pos += 2
+			segments = append(segments, segment{kind: segmentRecursive})
+			if p.pos < len(p.src) && p.src[p.pos] == '[' {
+				continue
+			}
+			seg, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, seg)
+		case c == '.':
+			p.pos++
+			seg, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, seg)
+		case c == '[':
+			seg, err := p.bracket()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, seg)
+		case first:
+			seg, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, seg)
+		default:
+			return nil, fmt.Errorf("unexpected %q at offset %d", c, p.pos)
+		}
+		first = false
+	}
+	return segments, nil
+}
+
+func (p *parser) name() (segment, error) {
+	start := p.pos
+	for p.pos < len(p.src) && p.src[p.pos] != '.' && p.src[p.pos] != '[' {
+		p.pos++
+	}
+	name := p.src[start:p.pos]
+	switch name {
+	case "":
+		return segment{}, fmt.Errorf("missing field name at offset %d", start)
+	case "*":
+		return segment{kind: segmentWildcard}, nil
+	}
+	return segment{kind: segmentKey, key: name}, nil
+}
+
+func (p *parser) bracket() (segment, error) {
+	start := p.pos
+	p.pos++ // '['
+	end, err := closingBracket(p.src, p.pos)
+	if err != nil {
+		return segment{}, fmt.Errorf("%w at offset %d", err, start)
+	}
+	body := strings.TrimSpace(p.src[p.pos:end])
+	p.pos = end + 1
+
+	switch {
+	case body == "*":
+		return segment{kind: segmentWildcard}, nil
+	case strings.HasPrefix(body, "?"):
+		expr := strings.TrimSpace(body[1:])
+		if !strings.HasPrefix(expr, "(") || !strings.HasSuffix(expr, ")") {
+			return segment{}, fmt.Errorf("filter must be wrapped in ?( ) at offset %d", start)
+		}
+		f, err := parseFilter(expr[1 : len(expr)-1])
+		if err != nil {
+			return segment{}, err
+		}
+		return segment{kind: segmentFilter, filter: f}, nil
+	case strings.HasPrefix(body, "'") || strings.HasPrefix(body, `"`):
+		key, err := unquote(body)
+		if err != nil {
+			return segment{}, fmt.Errorf("%w at offset %d", err, start)
+		}
+		return segment{kind: segmentKey, key: key}, nil
+	}
+	idx, err := strconv.Atoi(body)
+	if err != nil {
+		return segment{}, fmt.Errorf("invalid index %q at offset %d", body, start)
+	}
+	return indexSegment(idx), nil
+}
+
+// closingBracket finds the ']' matching an already consumed '[', skipping
+// quoted strings and nested brackets used inside filters.
+func closingBracket(src string, from int) (int, error) {
+	depth := 0
+	var quote byte
+	for i := from; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			if depth == 0 {
+				return i, nil
+			}
+			depth--
+		}
+	}
+	return 0, errors.New("unterminated '['")
+}
+
+func unquote(s string) (string, error) {
+	if len(s) < 2 || s[0] != s[len(s)-1] {
+		return "", fmt.Errorf("unterminated string %s", s)
+	}
+	if s[0] == '\'' {
+		s = `"` + strings.ReplaceAll(strings.ReplaceAll(s[1:len(s)-1], `"`, `\"`), `\'`, `'`) + `"`
+	}
+	return strconv.Unquote(s)
+}
+
+// filter is a disjunction of conjunctions of comparisons against the
+// current node (`@`).
+type filter struct {
+	any [][]comparison
+}
+
+type comparison struct {
+	left    *Path
+	op      string
+	literal any
+}
+
+var filterOperators = []string{"==", "!=", "<=", ">=", "=~", "<", ">"}
+
+func parseFilter(expr string) (*filter, error) {
+	f := &filter{}
+	for _, alt := range splitOutsideQuotes(expr, "||") {
+		var all []comparison
+		for _, term := range splitOutsideQuotes(alt, "&&") {
+			cmp, err := parseComparison(strings.TrimSpace(term))
+			if err != nil {
+				return nil, err
+			}
+			all = append(all, cmp)
+		}
+		f.any = append(f.any, all)
+	}
+	return f, nil
+}
+
+func parseComparison(term string) (comparison, error) {
+	left, op, right := term, "", ""
+	if idx, found := indexOperator(term); idx >= 0 {
+		left, op, right = strings.TrimSpace(term[:idx]), found, strings.TrimSpace(term[idx+len(found):])
+	}
+	if !strings.HasPrefix(left, "@") {
+		return comparison{}, fmt.Errorf("filter term %q must start with @", term)
+	}
+	sub, err := Compile("$" + left[1:])
+	if err != nil {
+		return comparison{}, err
+	}
+	cmp := comparison{left: sub, op: op}
+	if op == "" {
+		return cmp, nil
+	}
+	literal, err := parseLiteral(right)
+	if err != nil {
+		return comparison{}, fmt.Errorf("filter term %q: %w", term, err)
+	}
+	if op == "=~" {
+		pattern, ok := literal.(string)
+		if !ok {
+			return comparison{}, fmt.Errorf("filter term %q: =~ expects a quoted pattern", term)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return comparison{}, fmt.Errorf("filter term %q: %w", term, err)
+		}
+		literal = re
+	}
+	cmp.literal = literal
+	return cmp, nil
+}
+
+func indexOperator(term string) (int, string) {
+	var quote byte
+	for i := 0; i < len(term); i++ {
+		c := term[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '\'' || c == '"' {
+			quote = c
+			continue
+		}
+		for _, op := range filterOperators {
+			if strings.HasPrefix(term[i:], op) {
+				return i, op
+			}
+		}
+	}
+	return -1, ""
+}
+
+func splitOutsideQuotes(s, sep string) []string {
+	var parts []string
+	var quote byte
+	last := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '\'' || c == '"' {
+			quote = c
+			continue
+		}
+		if strings.HasPrefix(s[i:], sep) {
+			parts = append(parts, s[last:i])
+			i += len(sep) - 1
+			last = i + 1
+		}
+	}
+	return append(parts, s[last:])
+}
+
+func parseLiteral(s string) (any, error) {
+	switch s {
+	case "":
+		return nil, errors.New("missing value")
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	if s[0] == '\'' || s[0] == '"' {
+		return unquote(s)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid literal %s", s)
+	}
+	return f, nil
+}
+
+func (f *filter) match(node any) bool {
+	for _, all := range f.any {
+		ok := true
+		for _, cmp := range all {
+			if !cmp.match(node) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c comparison) match(node any) bool {
+	values := c.left.Find(node)
+	if c.op == "" {
+		return len(values) > 0
+	}
+	if len(values) == 0 {
+		return c.op == "!="
+	}
+	actual := values[0]
+	if c.op == "=~" {
+		s, ok := actual.(string)
+		return ok && c.literal.(*regexp.Regexp).MatchString(s)
+	}
+
+	an, aNum := toFloat(actual)
+	ln, lNum := toFloat(c.literal)
+	if aNum && lNum {
+		return compareOrdered(an, ln, c.op)
+	}
+	as, aStr := actual.(string)
+	ls, lStr := c.literal.(string)
+	if aStr && lStr {
+		return compareOrdered(as, ls, c.op)
+	}
+	equal := fmt.Sprint(actual) == fmt.Sprint(c.literal) && (actual == nil) == (c.literal == nil)
+	switch c.op {
+	case "==":
+		return equal
+	case "!=":
+		return !equal
+	}
+	return false
+}
+
+func compareOrdered[T float64 | string](a, b T, op string) bool {
+	switch op {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,553 @@
+package match
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Value checks actual against expected. Expected is either a literal, compared
+// loosely (numbers numerically, everything else by its printed form), a list
+// compared element-wise, an object whose keys must all match (extra actual
+// keys are ignored), or a matcher object made of operators such as
+//
+//	{"$gt": 0}
+//	{"$regex": "^[0-9a-f-]{36}$"}
+//	{"$approx": "now", "$tolerance": "5s"}
+//	{"$exists": false}
+//
+// Several operators in one object must all hold.
+func Value(actual any, expected any) error {
+	return Field(actual, true, expected)
+}
+
+// Field is Value for a lookup that may have found nothing; present reports
+// whether the field exists so `$exists` can be evaluated. A missing field
+// only matches `{"$exists": false}`.
+func Field(actual any, present bool, expected any) error {
+	ops, isMatcher, err := operators(expected)
+	if err != nil {
+		return err
+	}
+	if !isMatcher {
+		if !present {
+			return fmt.Errorf("field is absent, want %s", Format(expected))
+		}
+		return literal(actual, expected)
+	}
+
+	if want, ok := ops["$exists"]; ok {
+		wantExists, ok := want.(bool)
+		if !ok {
+			return fmt.Errorf("$exists expects a bool, got %s", Format(want))
+		}
+		if wantExists != present {
+			if present {
+				return fmt.Errorf("got %s, want field to be absent", Format(actual))
+			}
+			return fmt.Errorf("field is absent, want it to exist")
+		}
+		delete(ops, "$exists")
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	if !present {
+		return fmt.Errorf("field is absent, want %s", Format(expected))
+	}
+
+	names := make([]string, 0, len(ops))
+	for name := range ops {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if _, ok := ops["$tolerance"]; ok {
+		if _, ok := ops["$approx"]; !ok {
+			return fmt.Errorf("$tolerance requires $approx")
+		}
+	}
+	for _, name := range names {
+		if name == "$tolerance" {
+			continue
+		}
+		if err := apply(name, actual, ops[name], ops); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsMatcher reports whether v is an operator object rather than a literal.
+func IsMatcher(v any) bool {
+	_, ok, err := operators(v)
+	return ok && err == nil
+}
+
+// operators returns the operator map of a matcher object. Objects mixing
+// operators and plain keys are rejected as ambiguous.
+func operators(expected any) (map[string]any, bool, error) {
+	m, ok := expected.(map[string]any)
+	if !ok || len(m) == 0 {
+		return nil, false, nil
+	}
+	ops := map[string]any{}
+	plain := 0
+	for k, v := range m {
+		if strings.HasPrefix(k, "$") {
+			ops[k] = v
+		} else {
+			plain++
+		}
+	}
+	if len(ops) == 0 {
+		return nil, false, nil
+	}
+	if plain > 0 {
+		return nil, false, fmt.Errorf("matcher %s mixes operators and fields", Format(expected))
+	}
+	return ops, true, nil
+}
+
+func literal(actual, expected any) error {
+	switch want := expected.(type) {
+	case map[string]any:
+		got, ok := asMap(actual)
+		if !ok {
+			return fmt.Errorf("got %s, want object %s", Format(actual), Format(expected))
+		}
+		keys := make([]string, 0, len(want))
+		for k := range want {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v, present := got[k]
+			if err := Field(v, present, want[k]); err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+		}
+		return nil
+	case []any:
+		got, ok := asList(actual)
+		if !ok {
+			return fmt.Errorf("got %s, want array %s", Format(actual), Format(expected))
+		}
+		if len(got) != len(want) {
+			return fmt.Errorf("got %d elements, want %d", len(got), len(want))
+		}
+		for i := range want {
+			if err := Value(got[i], want[i]); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		return nil
+	}
+	if !Equal(actual, expected) {
+		return fmt.Errorf("got %s, want %s", Format(actual), Format(expected))
+	}
+	return nil
+}
+
+func apply(name string, actual, arg any, ops map[string]any) error {
+	switch name {
+	case "$eq":
+		return Value(actual, arg)
+	case "$notEqual", "$ne":
+		if Value(actual, arg) == nil {
+			return fmt.Errorf("got %s, want anything but %s", Format(actual), Format(arg))
+		}
+		return nil
+	case "$gt", "$gte", "$lt", "$lte":
+		return compare(name, actual, arg)
+	case "$regex":
+		pattern, ok := arg.(string)
+		if !ok {
+			return fmt.Errorf("$regex expects a string, got %s", Format(arg))
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("$regex: %w", err)
+		}
+		if !re.MatchString(fmt.Sprint(actual)) {
+			return fmt.Errorf("got %s, want match for /%s/", Format(actual), pattern)
+		}
+		return nil
+	case "$type":
+		want, ok := arg.(string)
+		if !ok {
+			return fmt.Errorf("$type expects a string, got %s", Format(arg))
+		}
+		if !hasType(actual, want) {
+			return fmt.Errorf("got %s (%s), want type %s", Format(actual), TypeOf(actual), want)
+		}
+		return nil
+	case "$in":
+		options, ok := asList(arg)
+		if !ok {
+			return fmt.Errorf("$in expects a list, got %s", Format(arg))
+		}
+		for _, option := range options {
+			if Value(actual, option) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("got %s, want one of %s", Format(actual), Format(arg))
+	case "$any":
+		items, ok := asList(actual)
+		if !ok {
+			return fmt.Errorf("got %s, want an array for $any", Format(actual))
+		}
+		for _, item := range items {
+			if Value(item, arg) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("no element of %s matches %s", Format(actual), Format(arg))
+	case "$len":
+		n, ok := length(actual)
+		if !ok {
+			return fmt.Errorf("got %s, which has no length", Format(actual))
+		}
+		if err := Value(n, arg); err != nil {
+			return fmt.Errorf("length %d: %w", n, err)
+		}
+		return nil
+	case "$approx":
+		return approx(actual, arg, ops["$tolerance"])
+	}
+	return fmt.Errorf("unknown matcher %s", name)
+}
+
+func compare(op string, actual, arg any) error {
+	var cmp int
+	if a, ok := toFloat(actual); ok {
+		b, ok := toFloat(arg)
+		if !ok {
+			return fmt.Errorf("%s expects a number, got %s", op, Format(arg))
+		}
+		cmp = compareFloat(a, b)
+	} else if a, ok := toTime(actual); ok {
+		b, ok := toTime(arg)
+		if !ok {
+			return fmt.Errorf("%s expects a time, got %s", op, Format(arg))
+		}
+		cmp = a.Compare(b)
+	} else if a, ok := actual.(string); ok {
+		cmp = strings.Compare(a, fmt.Sprint(arg))
+	} else {
+		return fmt.Errorf("got %s, which is not comparable with %s", Format(actual), op)
+	}
+
+	var ok bool
+	var symbol string
+	switch op {
+	case "$gt":
+		ok, symbol = cmp > 0, ">"
+	case "$gte":
+		ok, symbol = cmp >= 0, ">="
+	case "$lt":
+		ok, symbol = cmp < 0, "<"
+	case "$lte":
+		ok, symbol = cmp <= 0, "<="
+	}
+	if !ok {
+		return fmt.Errorf("got %s, want %s %s", Format(actual), symbol, Format(arg))
+	}
+	return nil
+}
+
+// approx compares numbers within an absolute tolerance (default 1e-9) or
+// times within a duration tolerance (default 1s). The time "now" is
+// resolved when the matcher runs.
+func approx(actual, arg, tolerance any) error {
+	if want, ok := toFloat(arg); ok {
+		got, ok := toFloat(actual)
+		if !ok {
+			return fmt.Errorf("got %s, want a number near %s", Format(actual), Format(arg))
+		}
+		tol := 1e-9
+		if tolerance != nil {
+			if tol, ok = toFloat(tolerance); !ok {
+				return fmt.Errorf("$tolerance expects a number, got %s", Format(tolerance))
+			}
+		}
+		if math.Abs(got-want) > tol {
+			return fmt.Errorf("got %s, want %s ± %s", Format(actual), Format(arg), Format(tol))
+		}
+		return nil
+	}
+
+	want, ok := toTime(arg)
+	if !ok {
+		return fmt.Errorf("$approx expects a number or time, got %s", Format(arg))
+	}
+	got, ok := toTime(actual)
+	if !ok {
+		return fmt.Errorf("got %s, want a time near %s", Format(actual), Format(arg))
+	}
+	tol := time.Second
+	if tolerance != nil {
+		d, err := toDuration(tolerance)
+		if err != nil {
+			return err
+		}
+		tol = d
+	}
+	diff := got.Sub(want)
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > tol {
+		return fmt.Errorf("got %s, want within %s of %s (off by %s)", got.Format(time.RFC3339Nano), tol, want.Format(time.RFC3339Nano), diff)
+	}
+	return nil
+}
+
+// Equal compares two values loosely: numbers by value, times by instant,
+// everything else by its printed representation. A json.Number, as produced
+// for database decimals, compares exactly with another json.Number or a
+// numeric string.
+func Equal(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if isNumber(a) || isNumber(b) {
+		if x, ok := toDecimal(a); ok {
+			if y, ok := toDecimal(b); ok {
+				return x.Cmp(y) == 0
+			}
+		}
+	}
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			return x == y
+		}
+	}
+	if x, ok := a.(time.Time); ok {
+		if y, ok := toTime(b); ok {
+			return x.Equal(y)
+		}
+	}
+	if y, ok := b.(time.Time); ok {
+		if x, ok := toTime(a); ok {
+			return x.Equal(y)
+		}
+	}
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// Format renders a value for failure messages.
+func Format(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("%q", val)
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case map[string]any, []any:
+		data, err := json.Marshal(val)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// TypeOf names the JSON-ish type of v as used by `$type`.
+func TypeOf(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case time.Time:
+		return "time"
+	}
+	if _, ok := toFloat(v); ok {
+		return "number"
+	}
+	if _, ok := asList(v); ok {
+		return "array"
+	}
+	if _, ok := asMap(v); ok {
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func hasType(v any, want string) bool {
+	switch strings.ToLower(want) {
+	case "uuid":
+		s, ok := v.(string)
+		return ok && uuidPattern.MatchString(s)
+	case "integer", "int":
+		f, ok := toFloat(v)
+		return ok && f == math.Trunc(f)
+	case "bool":
+		want = "boolean"
+	case "time", "date", "datetime", "timestamp":
+		_, ok := toTime(v)
+		return ok
+	}
+	return TypeOf(v) == strings.ToLower(want)
+}
+
+func length(v any) (int, bool) {
+	if s, ok := v.(string); ok {
+		return len([]rune(s)), true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len(), true
+	}
+	return 0, false
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func isNumber(v any) bool {
+	_, ok := v.(json.Number)
+	return ok
+}
+
+// toDecimal parses exact decimal representations: json.Number and numeric
+// strings. Binary floats are left to toFloat.
+func toDecimal(v any) (*big.Rat, bool) {
+	var s string
+	switch n := v.(type) {
+	case json.Number:
+		s = n.String()
+	case string:
+		s = n
+	default:
+		return nil, false
+	}
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	return r, ok
+}
+
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999Z07:00", "2006-01-02 15:04:05.999999999", "2006-01-02"}
+
+func toTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case string:
+		if strings.EqualFold(t, "now") {
+			return time.Now(), true
+		}
+		for _, layout := range timeLayouts {
+			if parsed, err := time.Parse(layout, t); err == nil {
+				return parsed, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func toDuration(v any) (time.Duration, error) {
+	switch d := v.(type) {
+	case string:
+		parsed, err := time.ParseDuration(d)
+		if err != nil {
+			return 0, fmt.Errorf("$tolerance: %w", err)
+		}
+		return parsed, nil
+	case time.Duration:
+		return d, nil
+	}
+	if f, ok := toFloat(v); ok {
+		return time.Duration(f * float64(time.Second)), nil
+	}
+	return 0, fmt.Errorf("$tolerance expects a duration, got %s", Format(v))
+}
+
+func asMap(v any) (map[string]any, bool) {
+	if m, ok := v.(map[string]any); ok {
+		return m, true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	out := make(map[string]any, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		out[iter.Key().String()] = iter.Value().Interface()
+	}
+	return out, true
+}
+
+func asList(v any) ([]any, bool) {
+	if list, ok := v.([]any); ok {
+		return list, true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	if rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	out := make([]any, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out, true
+}
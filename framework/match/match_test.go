@@ -0,0 +1,168 @@
+package match
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestField(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		actual   any
+		absent   bool
+		expected any
+		wantErr  string
+	}{
+		// $exists
+		{name: "$exists true", actual: "x", expected: map[string]any{"$exists": true}},
+		{name: "$exists true on null", actual: nil, expected: map[string]any{"$exists": true}},
+		{name: "$exists true when absent", absent: true, expected: map[string]any{"$exists": true}, wantErr: "field is absent, want it to exist"},
+		{name: "$exists false when absent", absent: true, expected: map[string]any{"$exists": false}},
+		{name: "$exists false when present", actual: 1, expected: map[string]any{"$exists": false}, wantErr: "want field to be absent"},
+		{name: "$exists with other operators", actual: 5, expected: map[string]any{"$exists": true, "$gt": 1}},
+		{name: "$exists not a bool", actual: 1, expected: map[string]any{"$exists": "yes"}, wantErr: "$exists expects a bool"},
+		{name: "operator on absent field", absent: true, expected: map[string]any{"$gt": 1}, wantErr: "field is absent"},
+
+		// $eq, $ne, $notEqual
+		{name: "$eq", actual: float64(3), expected: map[string]any{"$eq": 3}},
+		{name: "$eq mismatch", actual: "a", expected: map[string]any{"$eq": "b"}, wantErr: `got "a", want "b"`},
+		{name: "$eq object", actual: map[string]any{"a": 1, "b": 2}, expected: map[string]any{"$eq": map[string]any{"a": 1}}},
+		{name: "$ne", actual: "active", expected: map[string]any{"$ne": "expired"}},
+		{name: "$ne mismatch", actual: "active", expected: map[string]any{"$ne": "active"}, wantErr: `want anything but "active"`},
+		{name: "$notEqual", actual: 1, expected: map[string]any{"$notEqual": 2}},
+		{name: "$notEqual mismatch", actual: 2, expected: map[string]any{"$notEqual": 2}, wantErr: "want anything but 2"},
+
+		// $gt, $gte, $lt, $lte
+		{name: "$gt", actual: 10, expected: map[string]any{"$gt": 9.5}},
+		{name: "$gt equal", actual: 10, expected: map[string]any{"$gt": 10}, wantErr: "got 10, want > 10"},
+		{name: "$gte equal", actual: json.Number("10.00"), expected: map[string]any{"$gte": 10}},
+		{name: "$gte mismatch", actual: 9, expected: map[string]any{"$gte": 10}, wantErr: "want >= 10"},
+		{name: "$lt", actual: -1, expected: map[string]any{"$lt": 0}},
+		{name: "$lt mismatch", actual: 0, expected: map[string]any{"$lt": 0}, wantErr: "want < 0"},
+		{name: "$lte", actual: 0, expected: map[string]any{"$lte": 0}},
+		{name: "$lte mismatch", actual: 1, expected: map[string]any{"$lte": 0}, wantErr: "want <= 0"},
+		{name: "range", actual: 15, expected: map[string]any{"$gte": 10, "$lt": 20}},
+		{name: "range mismatch", actual: 20, expected: map[string]any{"$gte": 10, "$lt": 20}, wantErr: "want < 20"},
+		{name: "$gt times", actual: now, expected: map[string]any{"$gt": "2020-01-01T00:00:00Z"}},
+		{name: "$lt time strings", actual: "2024-01-01T00:00:00Z", expected: map[string]any{"$lt": "2024-06-01"}},
+		{name: "$gt strings", actual: "b", expected: map[string]any{"$gt": "a"}},
+		{name: "$gt number against string", actual: 1, expected: map[string]any{"$gt": "a"}, wantErr: "$gt expects a number"},
+		{name: "$gt not comparable", actual: true, expected: map[string]any{"$gt": 1}, wantErr: "not comparable"},
+
+		// $regex
+		{name: "$regex", actual: "bonus-42", expected: map[string]any{"$regex": `^bonus-\d+$`}},
+		{name: "$regex on number", actual: 42, expected: map[string]any{"$regex": `^4`}},
+		{name: "$regex mismatch", actual: "promo", expected: map[string]any{"$regex": "^bonus"}, wantErr: "want match for /^bonus/"},
+		{name: "$regex invalid", actual: "x", expected: map[string]any{"$regex": "("}, wantErr: "$regex: error parsing regexp"},
+		{name: "$regex not a string", actual: "x", expected: map[string]any{"$regex": 1}, wantErr: "$regex expects a string"},
+
+		// $type
+		{name: "$type string", actual: "x", expected: map[string]any{"$type": "string"}},
+		{name: "$type number", actual: json.Number("1.5"), expected: map[string]any{"$type": "number"}},
+		{name: "$type integer", actual: float64(3), expected: map[string]any{"$type": "integer"}},
+		{name: "$type integer mismatch", actual: 3.5, expected: map[string]any{"$type": "integer"}, wantErr: "want type integer"},
+		{name: "$type bool", actual: true, expected: map[string]any{"$type": "bool"}},
+		{name: "$type null", actual: nil, expected: map[string]any{"$type": "null"}},
+		{name: "$type array", actual: []any{1}, expected: map[string]any{"$type": "array"}},
+		{name: "$type object", actual: map[string]any{}, expected: map[string]any{"$type": "object"}},
+		{name: "$type uuid", actual: "3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b", expected: map[string]any{"$type": "uuid"}},
+		{name: "$type uuid mismatch", actual: "not-a-uuid", expected: map[string]any{"$type": "uuid"}, wantErr: `got "not-a-uuid" (string), want type uuid`},
+		{name: "$type time", actual: "2024-01-01T10:00:00Z", expected: map[string]any{"$type": "timestamp"}},
+		{name: "$type not a string", actual: 1, expected: map[string]any{"$type": 1}, wantErr: "$type expects a string"},
+
+		// $in
+		{name: "$in", actual: "active", expected: map[string]any{"$in": []any{"pending", "active"}}},
+		{name: "$in numbers", actual: json.Number("2.0"), expected: map[string]any{"$in": []any{1, 2}}},
+		{name: "$in mismatch", actual: "expired", expected: map[string]any{"$in": []any{"pending", "active"}}, wantErr: `want one of ["pending","active"]`},
+		{name: "$in not a list", actual: 1, expected: map[string]any{"$in": 1}, wantErr: "$in expects a list"},
+
+		// $any
+		{name: "$any literal", actual: []any{"a", "b"}, expected: map[string]any{"$any": "b"}},
+		{name: "$any object", actual: []any{map[string]any{"id": 1, "status": "x"}, map[string]any{"id": 2}}, expected: map[string]any{"$any": map[string]any{"id": 2}}},
+		{name: "$any matcher", actual: []any{1, 5}, expected: map[string]any{"$any": map[string]any{"$gt": 4}}},
+		{name: "$any mismatch", actual: []any{1, 2}, expected: map[string]any{"$any": 3}, wantErr: "no element of [1,2] matches 3"},
+		{name: "$any not an array", actual: "a", expected: map[string]any{"$any": "a"}, wantErr: "want an array for $any"},
+
+		// $len
+		{name: "$len array", actual: []any{1, 2, 3}, expected: map[string]any{"$len": 3}},
+		{name: "$len string", actual: "héllo", expected: map[string]any{"$len": 5}},
+		{name: "$len object", actual: map[string]any{"a": 1}, expected: map[string]any{"$len": 1}},
+		{name: "$len matcher", actual: []any{1, 2}, expected: map[string]any{"$len": map[string]any{"$gte": 1}}},
+		{name: "$len mismatch", actual: []any{1}, expected: map[string]any{"$len": 2}, wantErr: "length 1: got 1, want 2"},
+		{name: "$len without length", actual: 5, expected: map[string]any{"$len": 1}, wantErr: "has no length"},
+
+		// $approx, $tolerance
+		{name: "$approx number", actual: 0.1 + 0.2, expected: map[string]any{"$approx": 0.3}},
+		{name: "$approx number tolerance", actual: 10.4, expected: map[string]any{"$approx": 10, "$tolerance": 0.5}},
+		{name: "$approx number mismatch", actual: 11, expected: map[string]any{"$approx": 10, "$tolerance": 0.5}, wantErr: "want 10 ± 0.5"},
+		{name: "$approx now", actual: now.Add(-500 * time.Millisecond), expected: map[string]any{"$approx": "now"}},
+		{name: "$approx now tolerance", actual: now.Add(-3 * time.Second), expected: map[string]any{"$approx": "now", "$tolerance": "5s"}},
+		{name: "$approx time mismatch", actual: now.Add(-time.Minute), expected: map[string]any{"$approx": "now", "$tolerance": "5s"}, wantErr: "want within 5s of"},
+		{name: "$approx time string", actual: "2024-01-01T10:00:01Z", expected: map[string]any{"$approx": "2024-01-01T10:00:00Z"}},
+		{name: "$approx seconds tolerance", actual: "2024-01-01T10:00:02Z", expected: map[string]any{"$approx": "2024-01-01T10:00:00Z", "$tolerance": 3}},
+		{name: "$approx not a time", actual: "soon", expected: map[string]any{"$approx": "now"}, wantErr: "want a time near"},
+		{name: "$approx bad argument", actual: 1, expected: map[string]any{"$approx": "later"}, wantErr: "$approx expects a number or time"},
+		{name: "$tolerance bad duration", actual: now, expected: map[string]any{"$approx": "now", "$tolerance": "soon"}, wantErr: "$tolerance: time: invalid duration"},
+		{name: "$tolerance without $approx", actual: 1, expected: map[string]any{"$tolerance": 1}, wantErr: "$tolerance requires $approx"},
+
+		// matcher objects
+		{name: "unknown operator", actual: 1, expected: map[string]any{"$between": []any{0, 2}}, wantErr: "unknown matcher $between"},
+		{name: "operators mixed with fields", actual: map[string]any{}, expected: map[string]any{"$gt": 1, "id": 2}, wantErr: "mixes operators and fields"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Field(tt.actual, !tt.absent, tt.expected)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error containing %q", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValueLiterals(t *testing.T) {
+	tests := []struct {
+		name     string
+		actual   any
+		expected any
+		wantErr  string
+	}{
+		{name: "number types", actual: int64(100), expected: float64(100)},
+		{name: "exact decimals", actual: json.Number("100.00"), expected: 100},
+		{name: "decimal string", actual: json.Number("0.1"), expected: "0.10"},
+		{name: "decimal mismatch", actual: json.Number("100.01"), expected: 100, wantErr: "got 100.01, want 100"},
+		{name: "partial object", actual: map[string]any{"id": 1, "extra": true}, expected: map[string]any{"id": 1}},
+		{name: "nested field", actual: map[string]any{"bonus": map[string]any{"amount": 5}}, expected: map[string]any{"bonus": map[string]any{"amount": 6}}, wantErr: "bonus: amount: got 5, want 6"},
+		{name: "missing field", actual: map[string]any{}, expected: map[string]any{"id": 1}, wantErr: "id: field is absent"},
+		{name: "list", actual: []any{1, "a"}, expected: []any{1, "a"}},
+		{name: "list length", actual: []any{1}, expected: []any{1, 2}, wantErr: "got 1 elements, want 2"},
+		{name: "null", actual: nil, expected: nil},
+		{name: "null mismatch", actual: 0, expected: nil, wantErr: "got 0, want null"},
+		{name: "times", actual: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), expected: "2024-01-01T11:00:00+01:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Value(tt.actual, tt.expected)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package match
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Row matching modes of RowSpec.
+const (
+	AnyRowMatches = "anyRowMatches"
+	AllRowsMatch  = "allRowsMatch"
+	ExactlyRows   = "exactlyRows"
+)
+
+// maxRowDiffs caps the rows listed in a failure message.
+const maxRowDiffs = 10
+
+// RowSpec describes the expected result rows of a query. Each expected row is
+// matched with Value, so it lists only the columns it cares about and may use
+// matchers.
+//
+// AnyRowMatches (the default) passes when a single row matches every field of
+// Contains, AllRowsMatch when every row does. ExactlyRows requires the result
+// to be exactly Rows, in order when Ordered is set.
+type RowSpec struct {
+	Mode     string
+	Contains map[string]any
+	Rows     []map[string]any
+	Ordered  bool
+}
+
+// Check applies spec to rows and describes every mismatching row on failure.
+func (spec RowSpec) Check(rows []map[string]any) error {
+	switch spec.Mode {
+	case "", AnyRowMatches:
+		return anyRow(rows, spec.Contains)
+	case AllRowsMatch:
+		return allRows(rows, spec.Contains)
+	case ExactlyRows:
+		if spec.Ordered {
+			return orderedRows(rows, spec.Rows)
+		}
+		return unorderedRows(rows, spec.Rows)
+	}
+	return fmt.Errorf("unknown row match mode %q", spec.Mode)
+}
+
+// Validate reports a RowSpec that cannot be checked, such as an unknown mode
+// or Rows given to a mode that ignores them.
+func (spec RowSpec) Validate() error {
+	switch spec.Mode {
+	case "", AnyRowMatches, AllRowsMatch:
+		if len(spec.Rows) > 0 {
+			return fmt.Errorf("rows require match %s", ExactlyRows)
+		}
+		if spec.Ordered {
+			return fmt.Errorf("ordered requires match %s", ExactlyRows)
+		}
+	case ExactlyRows:
+		if len(spec.Contains) > 0 {
+			return fmt.Errorf("match %s compares rows, not contains", ExactlyRows)
+		}
+	default:
+		return fmt.Errorf("unknown row match mode %q (want %s, %s or %s)", spec.Mode, AnyRowMatches, AllRowsMatch, ExactlyRows)
+	}
+	return nil
+}
+
+func anyRow(rows []map[string]any, want map[string]any) error {
+	if len(rows) == 0 {
+		return fmt.Errorf("no rows returned, want one matching %s", Format(want))
+	}
+	diffs := make([]string, 0, len(rows))
+	for i, row := range rows {
+		err := Value(row, want)
+		if err == nil {
+			return nil
+		}
+		diffs = append(diffs, fmt.Sprintf("row %d: %v", i+1, err))
+	}
+	return fmt.Errorf("no row matches %s; %d rows:%s", Format(want), len(rows), list(diffs))
+}
+
+func allRows(rows []map[string]any, want map[string]any) error {
+	if len(rows) == 0 {
+		return fmt.Errorf("no rows returned, want all rows to match %s", Format(want))
+	}
+	var diffs []string
+	for i, row := range rows {
+		if err := Value(row, want); err != nil {
+			diffs = append(diffs, fmt.Sprintf("row %d: %v", i+1, err))
+		}
+	}
+	if len(diffs) > 0 {
+		return fmt.Errorf("%d of %d rows do not match %s:%s", len(diffs), len(rows), Format(want), list(diffs))
+	}
+	return nil
+}
+
+func orderedRows(rows []map[string]any, want []map[string]any) error {
+	var diffs []string
+	for i := 0; i < len(rows) || i < len(want); i++ {
+		switch {
+		case i >= len(rows):
+			diffs = append(diffs, fmt.Sprintf("row %d: missing, want %s", i+1, Format(want[i])))
+		case i >= len(want):
+			diffs = append(diffs, fmt.Sprintf("row %d: unexpected %s", i+1, Format(rows[i])))
+		default:
+			if err := Value(rows[i], want[i]); err != nil {
+				diffs = append(diffs, fmt.Sprintf("row %d: %v", i+1, err))
+			}
+		}
+	}
+	if len(diffs) > 0 {
+		return fmt.Errorf("rows differ (got %d, want %d):%s", len(rows), len(want), list(diffs))
+	}
+	return nil
+}
+
+// unorderedRows pairs expected and actual rows with a maximum bipartite
+// matching, so overlapping matchers cannot steal each other's rows.
+func unorderedRows(rows []map[string]any, want []map[string]any) error {
+	fits := make([][]int, len(want))
+	for i, w := range want {
+		for j, row := range rows {
+			if Value(row, w) == nil {
+				fits[i] = append(fits[i], j)
+			}
+		}
+	}
+	rowOf := make([]int, len(want))
+	wantOf := make([]int, len(rows))
+	for i := range rowOf {
+		rowOf[i] = -1
+	}
+	for j := range wantOf {
+		wantOf[j] = -1
+	}
+	var augment func(i int, seen []bool) bool
+	augment = func(i int, seen []bool) bool {
+		for _, j := range fits[i] {
+			if seen[j] {
+				continue
+			}
+			seen[j] = true
+			if wantOf[j] < 0 || augment(wantOf[j], seen) {
+				rowOf[i], wantOf[j] = j, i
+				return true
+			}
+		}
+		return false
+	}
+	for i := range want {
+		augment(i, make([]bool, len(rows)))
+	}
+
+	var diffs []string
+	for i, j := range rowOf {
+		if j < 0 {
+			diffs = append(diffs, "missing "+Format(want[i])+closest(rows, want[i]))
+		}
+	}
+	for j, i := range wantOf {
+		if i < 0 {
+			diffs = append(diffs, fmt.Sprintf("unexpected row %d: %s", j+1, Format(rows[j])))
+		}
+	}
+	if len(diffs) > 0 {
+		return fmt.Errorf("rows differ (got %d, want %d):%s", len(rows), len(want), list(diffs))
+	}
+	return nil
+}
+
+// closest explains why the first row failed to match want, which is usually
+// enough to spot a wrong value.
+func closest(rows []map[string]any, want map[string]any) string {
+	for i, row := range rows {
+		if err := Value(row, want); err != nil {
+			return fmt.Sprintf(" (row %d: %v)", i+1, err)
+		}
+	}
+	return ""
+}
+
+func list(diffs []string) string {
+	var b strings.Builder
+	for i, d := range diffs {
+		if i == maxRowDiffs {
+			fmt.Fprintf(&b, "\n  ... %d more", len(diffs)-i)
+			break
+		}
+		b.WriteString("\n  ")
+		b.WriteString(d)
+	}
+	return b.String()
+}
@@ -0,0 +1,215 @@
+package match
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRowSpecCheck(t *testing.T) {
+	rows := []map[string]any{
+		{"id": float64(1), "status": "active", "amount": float64(10)},
+		{"id": float64(2), "status": "expired", "amount": float64(25)},
+	}
+	tests := []struct {
+		name    string
+		spec    RowSpec
+		rows    []map[string]any
+		wantErr string
+	}{
+		{
+			name: "any row matches by default",
+			spec: RowSpec{Contains: map[string]any{"status": "expired"}},
+			rows: rows,
+		},
+		{
+			name: "any row with a matcher",
+			spec: RowSpec{Mode: AnyRowMatches, Contains: map[string]any{"amount": map[string]any{"$gt": 20}}},
+			rows: rows,
+		},
+		{
+			name: "no row matches",
+			spec: RowSpec{Contains: map[string]any{"status": "pending"}},
+			rows: rows,
+			wantErr: `no row matches {"status":"pending"}; 2 rows:
+  row 1: status: got "active", want "pending"
+  row 2: status: got "expired", want "pending"`,
+		},
+		{
+			name:    "any row of none",
+			spec:    RowSpec{Contains: map[string]any{"id": 1}},
+			wantErr: `no rows returned, want one matching {"id":1}`,
+		},
+		{
+			name: "all rows match",
+			spec: RowSpec{Mode: AllRowsMatch, Contains: map[string]any{"amount": map[string]any{"$gte": 10}}},
+			rows: rows,
+		},
+		{
+			name: "all rows lists only the mismatching ones",
+			spec: RowSpec{Mode: AllRowsMatch, Contains: map[string]any{"status": "active"}},
+			rows: rows,
+			wantErr: `1 of 2 rows do not match {"status":"active"}:
+  row 2: status: got "expired", want "active"`,
+		},
+		{
+			name:    "all rows of none",
+			spec:    RowSpec{Mode: AllRowsMatch, Contains: map[string]any{"id": 1}},
+			wantErr: `no rows returned, want all rows to match {"id":1}`,
+		},
+		{
+			name: "exactly rows in any order",
+			spec: RowSpec{Mode: ExactlyRows, Rows: []map[string]any{{"id": 2}, {"id": 1}}},
+			rows: rows,
+		},
+		{
+			name: "overlapping matchers need a full matching",
+			// A greedy pass gives row 1 to the first expectation and leaves
+			// nothing for {"amount": 10}.
+			spec: RowSpec{Mode: ExactlyRows, Rows: []map[string]any{
+				{"amount": map[string]any{"$gte": 10}},
+				{"amount": 10},
+			}},
+			rows: rows,
+		},
+		{
+			name: "overlapping matchers with three rows",
+			spec: RowSpec{Mode: ExactlyRows, Rows: []map[string]any{
+				{"id": map[string]any{"$in": []any{1, 2, 3}}},
+				{"id": map[string]any{"$in": []any{1, 2}}},
+				{"id": 1},
+			}},
+			rows: append(rows[:2:2], map[string]any{"id": float64(3)}),
+		},
+		{
+			name: "missing row with closest diff",
+			spec: RowSpec{Mode: ExactlyRows, Rows: []map[string]any{{"id": 1}, {"id": 2}, {"id": 3, "status": "active"}}},
+			rows: rows,
+			wantErr: `rows differ (got 2, want 3):
+  missing {"id":3,"status":"active"} (row 1: id: got 1, want 3)`,
+		},
+		{
+			name: "unexpected row",
+			spec: RowSpec{Mode: ExactlyRows, Rows: []map[string]any{{"id": 2}}},
+			rows: rows,
+			wantErr: `rows differ (got 2, want 1):
+  unexpected row 1: {"amount":10,"id":1,"status":"active"}`,
+		},
+		{
+			name: "missing and unexpected rows",
+			spec: RowSpec{Mode: ExactlyRows, Rows: []map[string]any{{"id": 1}, {"id": 2, "amount": 30}}},
+			rows: rows,
+			wantErr: `rows differ (got 2, want 2):
+  missing {"amount":30,"id":2} (row 1: amount: got 10, want 30)
+  unexpected row 2: {"amount":25,"id":2,"status":"expired"}`,
+		},
+		{
+			name: "empty rows list wants no rows",
+			spec: RowSpec{Mode: ExactlyRows, Rows: []map[string]any{}},
+		},
+		{
+			name: "empty rows list with rows returned",
+			spec: RowSpec{Mode: ExactlyRows, Rows: []map[string]any{}},
+			rows: rows[:1],
+			wantErr: `rows differ (got 1, want 0):
+  unexpected row 1: {"amount":10,"id":1,"status":"active"}`,
+		},
+		{
+			name: "ordered rows",
+			spec: RowSpec{Mode: ExactlyRows, Ordered: true, Rows: []map[string]any{{"id": 1}, {"id": 2}}},
+			rows: rows,
+		},
+		{
+			name: "ordered rows in the wrong order",
+			spec: RowSpec{Mode: ExactlyRows, Ordered: true, Rows: []map[string]any{{"id": 2}, {"id": 1}}},
+			rows: rows,
+			wantErr: `rows differ (got 2, want 2):
+  row 1: id: got 1, want 2
+  row 2: id: got 2, want 1`,
+		},
+		{
+			name: "ordered rows missing one",
+			spec: RowSpec{Mode: ExactlyRows, Ordered: true, Rows: []map[string]any{{"id": 1}, {"id": 2}, {"id": 3}}},
+			rows: rows,
+			wantErr: `rows differ (got 2, want 3):
+  row 3: missing, want {"id":3}`,
+		},
+		{
+			name: "ordered rows with an extra one",
+			spec: RowSpec{Mode: ExactlyRows, Ordered: true, Rows: []map[string]any{{"id": 1}}},
+			rows: rows,
+			wantErr: `rows differ (got 2, want 1):
+  row 2: unexpected {"amount":25,"id":2,"status":"expired"}`,
+		},
+		{
+			name: "ordered empty rows list",
+			spec: RowSpec{Mode: ExactlyRows, Ordered: true, Rows: []map[string]any{}},
+		},
+		{
+			name:    "unknown mode",
+			spec:    RowSpec{Mode: "someRows"},
+			wantErr: `unknown row match mode "someRows"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.spec.Check(tt.rows)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Check: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("error\n%v\nwant\n%s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRowSpecCheckCapsDiffs(t *testing.T) {
+	var rows []map[string]any
+	for i := 1; i <= 12; i++ {
+		rows = append(rows, map[string]any{"id": float64(i)})
+	}
+	err := RowSpec{Mode: AllRowsMatch, Contains: map[string]any{"id": 0}}.Check(rows)
+	if err == nil {
+		t.Fatal("Check passed")
+	}
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != maxRowDiffs+2 || lines[len(lines)-1] != "  ... 2 more" {
+		t.Fatalf("error lists %d lines:\n%v", len(lines), err)
+	}
+	if want := fmt.Sprintf("  row %d: id: got %d, want 0", maxRowDiffs, maxRowDiffs); lines[maxRowDiffs] != want {
+		t.Fatalf("line %q, want %q", lines[maxRowDiffs], want)
+	}
+}
+
+func TestRowSpecValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    RowSpec
+		wantErr string
+	}{
+		{name: "default mode", spec: RowSpec{Contains: map[string]any{"id": 1}}},
+		{name: "exactly rows", spec: RowSpec{Mode: ExactlyRows, Rows: []map[string]any{{"id": 1}}, Ordered: true}},
+		{name: "rows without exactlyRows", spec: RowSpec{Mode: AllRowsMatch, Rows: []map[string]any{{"id": 1}}}, wantErr: "rows require match exactlyRows"},
+		{name: "ordered without exactlyRows", spec: RowSpec{Ordered: true}, wantErr: "ordered requires match exactlyRows"},
+		{name: "contains with exactlyRows", spec: RowSpec{Mode: ExactlyRows, Contains: map[string]any{"id": 1}}, wantErr: "match exactlyRows compares rows, not contains"},
+		{name: "unknown mode", spec: RowSpec{Mode: "first"}, wantErr: `unknown row match mode "first"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.spec.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Package mq holds what the message broker clients share.
+package mq
+
+import (
+	"context"
+	"sync"
+)
+
+// Buffer collects items delivered in the background, such as the messages
+// of a subscription. The zero value is ready to use.
+type Buffer[T any] struct {
+	mu       sync.Mutex
+	received []T
+	notify   chan struct{}
+}
+
+// Add appends item and wakes up a pending Wait.
+func (b *Buffer[T]) Add(item T) {
+	b.mu.Lock()
+	b.received = append(b.received, item)
+	notify := b.notifyLocked()
+	b.mu.Unlock()
+	select {
+	case notify <- struct{}{}:
+	default:
+	}
+}
+
+// Received returns the items collected so far.
+func (b *Buffer[T]) Received() []T {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]T(nil), b.received...)
+}
+
+// Wait blocks until done reports true for the collected items or ctx ends,
+// and returns the items collected by then.
+func (b *Buffer[T]) Wait(ctx context.Context, done func([]T) bool) []T {
+	b.mu.Lock()
+	notify := b.notifyLocked()
+	b.mu.Unlock()
+	for {
+		items := b.Received()
+		if done(items) {
+			return items
+		}
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return b.Received()
+		}
+	}
+}
+
+func (b *Buffer[T]) notifyLocked() chan struct{} {
+	if b.notify == nil {
+		b.notify = make(chan struct{}, 1)
+	}
+	return b.notify
+}
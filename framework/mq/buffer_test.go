@@ -0,0 +1,47 @@
+package mq
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBufferWait(t *testing.T) {
+	tests := []struct {
+		name    string
+		added   []int
+		atLeast int
+		want    int
+	}{
+		{name: "already satisfied", added: []int{1, 2}, atLeast: 2, want: 2},
+		{name: "satisfied by later items", added: []int{1, 2, 3}, atLeast: 3, want: 3},
+		{name: "timeout returns what arrived", added: []int{1}, atLeast: 2, want: 1},
+		{name: "nothing arrives", atLeast: 1, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b Buffer[int]
+			go func() {
+				for _, item := range tt.added {
+					b.Add(item)
+				}
+			}()
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			got := b.Wait(ctx, func(items []int) bool { return len(items) >= tt.atLeast })
+			if len(got) != tt.want {
+				t.Fatalf("Wait returned %v, want %d items", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBufferReceivedIsACopy(t *testing.T) {
+	var b Buffer[string]
+	b.Add("a")
+	got := b.Received()
+	got[0] = "changed"
+	if b.Received()[0] != "a" {
+		t.Fatal("Received exposes the internal slice")
+	}
+}
@@ -0,0 +1,222 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	"github.com/example/go-test-framework/framework/mq"
+)
+
+// Broker produces and captures records. Client talks to a Kafka cluster and
+// Memory stands in for one.
+type Broker interface {
+	Produce(ctx context.Context, rec Record) error
+	Subscribe(ctx context.Context, topic string) (*Subscription, error)
+	Close() error
+}
+
+// Client produces and captures records on a Kafka cluster.
+type Client struct {
+	brokers []string
+	client  *kafkago.Client
+	writer  *kafkago.Writer
+}
+
+// New returns a client for a comma-separated broker list. Connections are
+// opened on first use.
+func New(_ context.Context, brokers string) (*Client, error) {
+	var addrs []string
+	for _, b := range strings.Split(brokers, ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			addrs = append(addrs, b)
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New("kafka brokers are required")
+	}
+	return &Client{
+		brokers: addrs,
+		client:  &kafkago.Client{Addr: kafkago.TCP(addrs...), Timeout: 10 * time.Second},
+		writer: &kafkago.Writer{
+			Addr:         kafkago.TCP(addrs...),
+			Balancer:     &kafkago.Hash{},
+			RequiredAcks: kafkago.RequireAll,
+		},
+	}, nil
+}
+
+func (c *Client) Close() error {
+	if c == nil || c.writer == nil {
+		return nil
+	}
+	return c.writer.Close()
+}
+
+// Record is a record to produce or one that was consumed.
+type Record struct {
+	Topic     string            `json:"topic"`
+	Partition int               `json:"partition"`
+	Offset    int64             `json:"offset"`
+	Key       string            `json:"key,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Value     []byte            `json:"value"`
+}
+
+// JSON decodes the value, or returns it as a string when it is not JSON.
+func (r Record) JSON() any {
+	var v any
+	if err := json.Unmarshal(r.Value, &v); err != nil {
+		return string(r.Value)
+	}
+	return v
+}
+
+// Produce writes rec and waits until all in-sync replicas acknowledged it.
+func (c *Client) Produce(ctx context.Context, rec Record) error {
+	msg := kafkago.Message{Topic: rec.Topic, Value: rec.Value}
+	if rec.Key != "" {
+		msg.Key = []byte(rec.Key)
+	}
+	for _, k := range sortedKeys(rec.Headers) {
+		msg.Headers = append(msg.Headers, kafkago.Header{Key: k, Value: []byte(rec.Headers[k])})
+	}
+	return c.writer.WriteMessages(ctx, msg)
+}
+
+// Subscription collects the records appended to a topic after it was
+// created.
+type Subscription struct {
+	mq.Buffer[Record]
+	close func() error
+}
+
+// Subscribe captures the current end offset of every partition of topic and
+// starts reading from there in the background.
+func (c *Client) Subscribe(ctx context.Context, topic string) (*Subscription, error) {
+	offsets, err := c.endOffsets(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+	readCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	var (
+		readers []*kafkago.Reader
+		wg      sync.WaitGroup
+	)
+	s := &Subscription{}
+	s.close = func() error {
+		cancel()
+		var errs []error
+		for _, r := range readers {
+			errs = append(errs, r.Close())
+		}
+		wg.Wait()
+		return errors.Join(errs...)
+	}
+	for _, partition := range sortedPartitions(offsets) {
+		r := kafkago.NewReader(kafkago.ReaderConfig{
+			Brokers:   c.brokers,
+			Topic:     topic,
+			Partition: partition,
+			MinBytes:  1,
+			MaxBytes:  10e6,
+			MaxWait:   250 * time.Millisecond,
+		})
+		if err := r.SetOffset(offsets[partition]); err != nil {
+			_ = r.Close()
+			_ = s.Close()
+			return nil, fmt.Errorf("topic %s partition %d: %w", topic, partition, err)
+		}
+		readers = append(readers, r)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.read(readCtx, r)
+		}()
+	}
+	return s, nil
+}
+
+func (s *Subscription) read(ctx context.Context, r *kafkago.Reader) {
+	for {
+		msg, err := r.ReadMessage(ctx)
+		if err != nil {
+			return
+		}
+		rec := Record{Topic: msg.Topic, Partition: msg.Partition, Offset: msg.Offset, Key: string(msg.Key), Value: msg.Value}
+		for _, h := range msg.Headers {
+			if rec.Headers == nil {
+				rec.Headers = map[string]string{}
+			}
+			rec.Headers[h.Key] = string(h.Value)
+		}
+		s.Add(rec)
+	}
+}
+
+// Close stops collecting records.
+func (s *Subscription) Close() error {
+	if s == nil || s.close == nil {
+		return nil
+	}
+	return s.close()
+}
+
+func (c *Client) endOffsets(ctx context.Context, topic string) (map[int]int64, error) {
+	meta, err := c.client.Metadata(ctx, &kafkago.MetadataRequest{Topics: []string{topic}})
+	if err != nil {
+		return nil, err
+	}
+	var requests []kafkago.OffsetRequest
+	for _, t := range meta.Topics {
+		if t.Name != topic {
+			continue
+		}
+		if t.Error != nil {
+			return nil, fmt.Errorf("topic %s: %w", topic, t.Error)
+		}
+		for _, p := range t.Partitions {
+			requests = append(requests, kafkago.LastOffsetOf(p.ID))
+		}
+	}
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("topic %s has no partitions", topic)
+	}
+	res, err := c.client.ListOffsets(ctx, &kafkago.ListOffsetsRequest{Topics: map[string][]kafkago.OffsetRequest{topic: requests}})
+	if err != nil {
+		return nil, err
+	}
+	offsets := map[int]int64{}
+	for _, p := range res.Topics[topic] {
+		if p.Error != nil {
+			return nil, fmt.Errorf("topic %s partition %d: %w", topic, p.Partition, p.Error)
+		}
+		offsets[p.Partition] = p.LastOffset
+	}
+	return offsets, nil
+}
+
+func sortedPartitions(offsets map[int]int64) []int {
+	ids := make([]int, 0, len(offsets))
+	for id := range offsets {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
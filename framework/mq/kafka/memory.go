@@ -0,0 +1,79 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Memory is an in-process Broker with single-partition topics, for running
+// produce steps and record assertions without a Kafka cluster. Like Client,
+// a subscription only sees records produced after it was created.
+type Memory struct {
+	mu     sync.Mutex
+	topics map[string][]Record
+	subs   map[string][]*Subscription
+	closed bool
+}
+
+// NewMemory returns an empty in-process broker.
+func NewMemory() *Memory {
+	return &Memory{topics: map[string][]Record{}, subs: map[string][]*Subscription{}}
+}
+
+// Produce appends rec to its topic and delivers it to the topic's
+// subscriptions.
+func (m *Memory) Produce(_ context.Context, rec Record) error {
+	if rec.Topic == "" {
+		return errors.New("kafka topic is required")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return errors.New("kafka broker is closed")
+	}
+	rec.Partition, rec.Offset = 0, int64(len(m.topics[rec.Topic]))
+	m.topics[rec.Topic] = append(m.topics[rec.Topic], rec)
+	for _, s := range m.subs[rec.Topic] {
+		s.Add(rec)
+	}
+	return nil
+}
+
+// Subscribe starts collecting the records produced to topic from now on.
+func (m *Memory) Subscribe(_ context.Context, topic string) (*Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil, errors.New("kafka broker is closed")
+	}
+	s := &Subscription{}
+	s.close = func() error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		subs := m.subs[topic]
+		for i, sub := range subs {
+			if sub == s {
+				m.subs[topic] = append(subs[:i:i], subs[i+1:]...)
+				break
+			}
+		}
+		return nil
+	}
+	m.subs[topic] = append(m.subs[topic], s)
+	return s, nil
+}
+
+// Records returns every record produced to topic.
+func (m *Memory) Records(topic string) []Record {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Record(nil), m.topics[topic]...)
+}
+
+func (m *Memory) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
@@ -0,0 +1,170 @@
+package kafka
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMemorySubscription(t *testing.T) {
+	tests := []struct {
+		name   string
+		before []Record
+		after  []Record
+		topic  string
+		want   []string
+	}{
+		{
+			name:  "records produced after subscribing",
+			after: []Record{{Topic: "bonus", Value: []byte("1")}, {Topic: "bonus", Value: []byte("2")}},
+			topic: "bonus",
+			want:  []string{"1", "2"},
+		},
+		{
+			name:   "records already on the topic are ignored",
+			before: []Record{{Topic: "bonus", Value: []byte("old")}},
+			after:  []Record{{Topic: "bonus", Value: []byte("new")}},
+			topic:  "bonus",
+			want:   []string{"new"},
+		},
+		{
+			name:  "other topics are ignored",
+			after: []Record{{Topic: "payments", Value: []byte("1")}},
+			topic: "bonus",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			m := NewMemory()
+			for _, rec := range tt.before {
+				if err := m.Produce(ctx, rec); err != nil {
+					t.Fatal(err)
+				}
+			}
+			sub, err := m.Subscribe(ctx, tt.topic)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sub.Close()
+			for _, rec := range tt.after {
+				if err := m.Produce(ctx, rec); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got := sub.Received()
+			if len(got) != len(tt.want) {
+				t.Fatalf("received %d records, want %d", len(got), len(tt.want))
+			}
+			for i, rec := range got {
+				if string(rec.Value) != tt.want[i] {
+					t.Errorf("record %d value = %s, want %s", i, rec.Value, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryOffsets(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	for i := 0; i < 3; i++ {
+		if err := m.Produce(ctx, Record{Topic: "bonus", Key: "k"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, rec := range m.Records("bonus") {
+		if rec.Partition != 0 || rec.Offset != int64(i) {
+			t.Errorf("record %d at %d/%d, want 0/%d", i, rec.Partition, rec.Offset, i)
+		}
+	}
+}
+
+func TestMemoryClosedSubscription(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	sub, err := m.Subscribe(ctx, "bonus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Produce(ctx, Record{Topic: "bonus"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := sub.Received(); len(got) != 0 {
+		t.Fatalf("closed subscription received %v", got)
+	}
+}
+
+func TestSubscriptionWait(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	sub, err := m.Subscribe(ctx, "bonus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = m.Produce(ctx, Record{Topic: "bonus", Key: "b-1"})
+	}()
+	waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	got := sub.Wait(waitCtx, func(recs []Record) bool { return len(recs) > 0 })
+	if len(got) != 1 || got[0].Key != "b-1" {
+		t.Fatalf("Wait returned %v", got)
+	}
+}
+
+func TestRecordJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  any
+	}{
+		{name: "object", value: `{"id":"b-1"}`, want: map[string]any{"id": "b-1"}},
+		{name: "number", value: `42`, want: float64(42)},
+		{name: "not json", value: `plain`, want: "plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Record{Value: []byte(tt.value)}.JSON()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("JSON() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewBrokers(t *testing.T) {
+	tests := []struct {
+		brokers string
+		want    []string
+		wantErr bool
+	}{
+		{brokers: "localhost:9092", want: []string{"localhost:9092"}},
+		{brokers: " a:9092, b:9092 ,", want: []string{"a:9092", "b:9092"}},
+		{brokers: " , ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.brokers, func(t *testing.T) {
+			c, err := New(context.Background(), tt.brokers)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+			if !reflect.DeepEqual(c.brokers, tt.want) {
+				t.Fatalf("brokers = %v, want %v", c.brokers, tt.want)
+			}
+		})
+	}
+}
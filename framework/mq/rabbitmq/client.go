@@ -0,0 +1,204 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/example/go-test-framework/framework/mq"
+)
+
+// Broker publishes and captures messages. Client talks to a RabbitMQ broker
+// and Memory stands in for one.
+type Broker interface {
+	Publish(ctx context.Context, msg Message) error
+	Subscribe(ctx context.Context, exchange, routingKey string) (*Subscription, error)
+	Close() error
+}
+
+// Client publishes and captures messages on a RabbitMQ broker.
+type Client struct {
+	conn *amqp.Connection
+}
+
+// New dials url, an amqp:// URL.
+func New(_ context.Context, url string) (*Client, error) {
+	if url == "" {
+		return nil, errors.New("rabbitmq url is required")
+	}
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn}, nil
+}
+
+func (c *Client) Close() error {
+	if c == nil || c.conn == nil || c.conn.IsClosed() {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// Message is a message to publish or one that was received.
+type Message struct {
+	Exchange   string         `json:"exchange"`
+	RoutingKey string         `json:"routingKey"`
+	Headers    map[string]any `json:"headers,omitempty"`
+	Body       []byte         `json:"body"`
+}
+
+// JSON decodes the body, or returns it as a string when it is not JSON.
+func (m Message) JSON() any {
+	var v any
+	if err := json.Unmarshal(m.Body, &v); err != nil {
+		return string(m.Body)
+	}
+	return v
+}
+
+// Publish sends msg and waits for the broker to confirm it.
+func (c *Client) Publish(ctx context.Context, msg Message) error {
+	ch, err := c.conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+	if err := ch.Confirm(false); err != nil {
+		return err
+	}
+	contentType := "application/octet-stream"
+	if json.Valid(msg.Body) {
+		contentType = "application/json"
+	}
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, msg.Exchange, msg.RoutingKey, false, false, amqp.Publishing{
+		ContentType: contentType,
+		Headers:     headerTable(msg.Headers),
+		Body:        msg.Body,
+	})
+	if err != nil {
+		return err
+	}
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !acked {
+		return fmt.Errorf("broker rejected message to %s/%s", msg.Exchange, msg.RoutingKey)
+	}
+	return nil
+}
+
+// Subscription captures every message routed to a temporary queue bound to
+// an exchange. The queue is exclusive and deleted on Close.
+type Subscription struct {
+	mq.Buffer[Message]
+	close func() error
+}
+
+// Subscribe binds a temporary queue to exchange with routingKey (wildcards
+// work on topic exchanges) and starts collecting messages in the background.
+func (c *Client) Subscribe(_ context.Context, exchange, routingKey string) (*Subscription, error) {
+	ch, err := c.conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+	q, err := ch.QueueDeclare("", false, true, true, false, nil)
+	if err == nil {
+		err = ch.QueueBind(q.Name, routingKey, exchange, false, nil)
+	}
+	var deliveries <-chan amqp.Delivery
+	if err == nil {
+		deliveries, err = ch.Consume(q.Name, "", true, true, false, false, nil)
+	}
+	if err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("bind %s/%s: %w", exchange, routingKey, err)
+	}
+
+	s := &Subscription{close: ch.Close}
+	go func() {
+		for d := range deliveries {
+			s.Add(Message{
+				Exchange:   d.Exchange,
+				RoutingKey: d.RoutingKey,
+				Headers:    headerMap(d.Headers),
+				Body:       d.Body,
+			})
+		}
+	}()
+	return s, nil
+}
+
+// Close deletes the temporary queue.
+func (s *Subscription) Close() error {
+	if s == nil || s.close == nil {
+		return nil
+	}
+	return s.close()
+}
+
+// headerTable converts headers to an AMQP table. Nested maps become tables
+// and lists []any, as amqp091 accepts no other container types.
+func headerTable(headers map[string]any) amqp.Table {
+	if headers == nil {
+		return nil
+	}
+	t := make(amqp.Table, len(headers))
+	for k, v := range headers {
+		t[k] = tableValue(v)
+	}
+	return t
+}
+
+func tableValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		return headerTable(v)
+	case amqp.Table:
+		return headerTable(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = tableValue(item)
+		}
+		return out
+	case []string:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = item
+		}
+		return out
+	}
+	return v
+}
+
+// headerMap is the reverse of headerTable, so received headers match like
+// decoded JSON.
+func headerMap(t amqp.Table) map[string]any {
+	if t == nil {
+		return nil
+	}
+	m := make(map[string]any, len(t))
+	for k, v := range t {
+		m[k] = mapValue(v)
+	}
+	return m
+}
+
+func mapValue(v any) any {
+	switch v := v.(type) {
+	case amqp.Table:
+		return headerMap(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = mapValue(item)
+		}
+		return out
+	}
+	return v
+}
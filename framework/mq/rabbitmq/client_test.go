@@ -0,0 +1,52 @@
+package rabbitmq
+
+import (
+	"reflect"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestHeaderTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]any
+		want    amqp.Table
+	}{
+		{name: "nil", headers: nil, want: nil},
+		{
+			name:    "scalars",
+			headers: map[string]any{"type": "bonus.created", "amount": float64(100), "retry": true, "none": nil},
+			want:    amqp.Table{"type": "bonus.created", "amount": float64(100), "retry": true, "none": nil},
+		},
+		{
+			name:    "nested map",
+			headers: map[string]any{"meta": map[string]any{"a": float64(1), "b": map[string]any{"c": "d"}}},
+			want:    amqp.Table{"meta": amqp.Table{"a": float64(1), "b": amqp.Table{"c": "d"}}},
+		},
+		{
+			name:    "lists",
+			headers: map[string]any{"tags": []any{"new", map[string]any{"a": "b"}}, "names": []string{"x"}},
+			want:    amqp.Table{"tags": []any{"new", amqp.Table{"a": "b"}}, "names": []any{"x"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := headerTable(tt.headers)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("headerTable = %#v, want %#v", got, tt.want)
+			}
+			if err := got.Validate(); err != nil {
+				t.Fatalf("amqp rejects the table: %v", err)
+			}
+		})
+	}
+}
+
+func TestHeaderMap(t *testing.T) {
+	got := headerMap(amqp.Table{"meta": amqp.Table{"a": int32(1)}, "tags": []any{amqp.Table{"b": "c"}}})
+	want := map[string]any{"meta": map[string]any{"a": int32(1)}, "tags": []any{map[string]any{"b": "c"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("headerMap = %#v, want %#v", got, want)
+	}
+}
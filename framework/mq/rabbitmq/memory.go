@@ -0,0 +1,123 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Memory is an in-process Broker for running publish steps and message
+// assertions without RabbitMQ. Every exchange routes like a topic exchange,
+// so binding keys may use * and #. Like Client, a subscription only sees
+// messages published after it was created.
+type Memory struct {
+	mu        sync.Mutex
+	published map[string][]Message
+	bindings  []*binding
+	closed    bool
+}
+
+type binding struct {
+	exchange   string
+	routingKey string
+	sub        *Subscription
+}
+
+// NewMemory returns an empty in-process broker.
+func NewMemory() *Memory {
+	return &Memory{published: map[string][]Message{}}
+}
+
+// Publish delivers msg to every subscription whose binding matches it.
+// Headers are checked like Client does, so values RabbitMQ would reject fail
+// here too.
+func (m *Memory) Publish(_ context.Context, msg Message) error {
+	if err := headerTable(msg.Headers).Validate(); err != nil {
+		return err
+	}
+	msg.Headers = headerMap(headerTable(msg.Headers))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return errors.New("rabbitmq broker is closed")
+	}
+	m.published[msg.Exchange] = append(m.published[msg.Exchange], msg)
+	for _, b := range m.bindings {
+		if b.exchange == msg.Exchange && topicMatch(b.routingKey, msg.RoutingKey) {
+			b.sub.Add(msg)
+		}
+	}
+	return nil
+}
+
+// Subscribe starts collecting the messages published to exchange with a
+// routing key matching routingKey.
+func (m *Memory) Subscribe(_ context.Context, exchange, routingKey string) (*Subscription, error) {
+	if exchange == "" {
+		return nil, errors.New("rabbitmq exchange is required")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil, errors.New("rabbitmq broker is closed")
+	}
+	b := &binding{exchange: exchange, routingKey: routingKey, sub: &Subscription{}}
+	b.sub.close = func() error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for i, other := range m.bindings {
+			if other == b {
+				m.bindings = append(m.bindings[:i:i], m.bindings[i+1:]...)
+				break
+			}
+		}
+		return nil
+	}
+	m.bindings = append(m.bindings, b)
+	return b.sub, nil
+}
+
+// Published returns every message published to exchange.
+func (m *Memory) Published(exchange string) []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.published[exchange]...)
+}
+
+func (m *Memory) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
+
+// topicMatch reports whether a topic exchange routes key to a queue bound
+// with pattern: * matches one word and # zero or more.
+func topicMatch(pattern, key string) bool {
+	return matchWords(strings.Split(pattern, "."), strings.Split(key, "."))
+}
+
+func matchWords(pattern, key []string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case "#":
+			for i := 0; i <= len(key); i++ {
+				if matchWords(pattern[1:], key[i:]) {
+					return true
+				}
+			}
+			return false
+		case "*":
+			if len(key) == 0 {
+				return false
+			}
+		default:
+			if len(key) == 0 || key[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, key = pattern[1:], key[1:]
+	}
+	return len(key) == 0
+}
@@ -0,0 +1,129 @@
+package rabbitmq
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTopicMatch(t *testing.T) {
+	tests := []struct {
+		pattern, key string
+		want         bool
+	}{
+		{pattern: "bonus.created", key: "bonus.created", want: true},
+		{pattern: "bonus.created", key: "bonus.expired", want: false},
+		{pattern: "bonus.*", key: "bonus.created", want: true},
+		{pattern: "bonus.*", key: "bonus.created.eu", want: false},
+		{pattern: "bonus.*", key: "bonus", want: false},
+		{pattern: "*.created", key: "bonus.created", want: true},
+		{pattern: "bonus.#", key: "bonus", want: true},
+		{pattern: "bonus.#", key: "bonus.created.eu", want: true},
+		{pattern: "#", key: "anything.at.all", want: true},
+		{pattern: "#.eu", key: "bonus.created.eu", want: true},
+		{pattern: "#.eu", key: "bonus.created.us", want: false},
+		{pattern: "bonus.#.eu", key: "bonus.eu", want: true},
+		{pattern: "", key: "", want: true},
+		{pattern: "", key: "bonus", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+"→"+tt.key, func(t *testing.T) {
+			if got := topicMatch(tt.pattern, tt.key); got != tt.want {
+				t.Fatalf("topicMatch(%q, %q) = %v, want %v", tt.pattern, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemorySubscription(t *testing.T) {
+	tests := []struct {
+		name       string
+		before     []Message
+		after      []Message
+		exchange   string
+		routingKey string
+		want       []string
+	}{
+		{
+			name:       "messages published after subscribing",
+			after:      []Message{{Exchange: "bonus.events", RoutingKey: "bonus.created", Body: []byte("1")}, {Exchange: "bonus.events", RoutingKey: "bonus.created", Body: []byte("2")}},
+			exchange:   "bonus.events",
+			routingKey: "bonus.created",
+			want:       []string{"1", "2"},
+		},
+		{
+			name:       "earlier messages are ignored",
+			before:     []Message{{Exchange: "bonus.events", RoutingKey: "bonus.created", Body: []byte("old")}},
+			after:      []Message{{Exchange: "bonus.events", RoutingKey: "bonus.created", Body: []byte("new")}},
+			exchange:   "bonus.events",
+			routingKey: "bonus.created",
+			want:       []string{"new"},
+		},
+		{
+			name:       "wildcard binding",
+			after:      []Message{{Exchange: "bonus.events", RoutingKey: "bonus.created", Body: []byte("1")}, {Exchange: "bonus.events", RoutingKey: "wallet.updated", Body: []byte("2")}},
+			exchange:   "bonus.events",
+			routingKey: "bonus.*",
+			want:       []string{"1"},
+		},
+		{
+			name:       "other exchanges are ignored",
+			after:      []Message{{Exchange: "payments", RoutingKey: "bonus.created", Body: []byte("1")}},
+			exchange:   "bonus.events",
+			routingKey: "#",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			m := NewMemory()
+			for _, msg := range tt.before {
+				if err := m.Publish(ctx, msg); err != nil {
+					t.Fatal(err)
+				}
+			}
+			sub, err := m.Subscribe(ctx, tt.exchange, tt.routingKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sub.Close()
+			for _, msg := range tt.after {
+				if err := m.Publish(ctx, msg); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got := sub.Received()
+			if len(got) != len(tt.want) {
+				t.Fatalf("received %d messages, want %d", len(got), len(tt.want))
+			}
+			for i, msg := range got {
+				if string(msg.Body) != tt.want[i] {
+					t.Errorf("message %d body = %s, want %s", i, msg.Body, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryRejects(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	if _, err := m.Subscribe(ctx, "", "bonus.created"); err == nil {
+		t.Fatal("subscribing to the default exchange succeeded")
+	}
+	if err := m.Publish(ctx, Message{Exchange: "bonus.events", Headers: map[string]any{"bad": struct{}{}}}); err == nil {
+		t.Fatal("publishing an invalid header succeeded")
+	}
+	sub, err := m.Subscribe(ctx, "bonus.events", "#")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Publish(ctx, Message{Exchange: "bonus.events", RoutingKey: "bonus.created"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := sub.Received(); len(got) != 0 {
+		t.Fatalf("closed subscription received %v", got)
+	}
+}
@@ -0,0 +1,163 @@
+package report
+
+import (
+	"encoding/json"
+	"html/template"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/example/go-test-framework/framework/result"
+)
+
+// WriteHTML renders suites as a single self-contained HTML page: styles are
+// inlined and nothing is loaded from the network, so the file can be
+// attached to CI runs as-is.
+func WriteHTML(w io.Writer, suites []*result.SuiteResult) error {
+	data := htmlReport{GeneratedAt: time.Now().UTC(), Suites: suites}
+	for _, s := range suites {
+		for status, n := range s.Counts() {
+			switch status {
+			case result.StatusPass:
+				data.Passed += n
+			case result.StatusFail:
+				data.Failed += n
+			case result.StatusError:
+				data.Errored += n
+			case result.StatusSkip:
+				data.Skipped += n
+			}
+		}
+		data.Duration += s.Duration
+	}
+	return htmlTemplate.Execute(w, data)
+}
+
+// WriteHTMLFile writes the HTML report to path.
+func WriteHTMLFile(path string, suites []*result.SuiteResult) error {
+	return writeFile(path, suites, WriteHTML)
+}
+
+type htmlReport struct {
+	GeneratedAt time.Time
+	Duration    time.Duration
+	Passed      int
+	Failed      int
+	Errored     int
+	Skipped     int
+	Suites      []*result.SuiteResult
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"ms": func(d time.Duration) string { return d.Round(time.Millisecond).String() },
+	"json": func(v any) string {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err.Error()
+		}
+		return string(data)
+	},
+	"pretty": func(s string) string {
+		var v any
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			return s
+		}
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return s
+		}
+		return string(data)
+	},
+	"keys": func(m map[string]string) []string {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return keys
+	},
+	"failed": func(s result.Status) bool { return s == result.StatusFail || s == result.StatusError },
+	"inc":    func(i int) int { return i + 1 },
+}).Parse(htmlSource))
+
+const htmlSource = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Test run {{.GeneratedAt.Format "2006-01-02 15:04:05"}} UTC</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; margin: 2rem; color: #1f2328; }
+h1 { font-size: 1.4rem; }
+.summary span { margin-right: 1rem; }
+details { border: 1px solid #d0d7de; border-radius: 6px; margin: .5rem 0; padding: .4rem .8rem; }
+details details { margin-left: 1rem; }
+summary { cursor: pointer; }
+.badge { display: inline-block; min-width: 3rem; text-align: center; border-radius: 4px; padding: 0 .4rem; font-size: .8rem; font-weight: 600; color: #fff; }
+.pass { background: #1a7f37; } .fail { background: #cf222e; } .error { background: #bc4c00; } .skip { background: #6e7781; }
+.muted { color: #6e7781; font-size: .85rem; }
+pre { background: #f6f8fa; padding: .6rem; border-radius: 4px; overflow-x: auto; white-space: pre-wrap; word-break: break-all; }
+.failure { color: #cf222e; }
+table { border-collapse: collapse; font-size: .85rem; }
+td, th { border: 1px solid #d0d7de; padding: .2rem .5rem; text-align: left; vertical-align: top; }
+</style>
+</head>
+<body>
+<h1>Test run report</h1>
+<p class="summary">
+<span>Generated {{.GeneratedAt.Format "2006-01-02 15:04:05"}} UTC</span>
+<span>Duration {{ms .Duration}}</span>
+<span class="badge pass">{{.Passed}}</span> passed
+<span class="badge fail">{{.Failed}}</span> failed
+<span class="badge error">{{.Errored}}</span> errors
+<span class="badge skip">{{.Skipped}}</span> skipped
+</p>
+{{range .Suites}}
+<details{{if failed .Status}} open{{end}}>
+<summary><span class="badge {{.Status}}">{{.Status}}</span> <strong>{{if .Name}}{{.Name}}{{else}}{{.ID}}{{end}}</strong> <span class="muted">{{.ID}} · {{ms .Duration}}</span></summary>
+{{if .Failure}}<pre class="failure">{{.Failure}}</pre>{{end}}
+{{range .Tests}}
+<details{{if failed .Status}} open{{end}}>
+<summary><span class="badge {{.Status}}">{{.Status}}</span> {{.Name}} <span class="muted">{{.Type}}{{if .Service}} · {{.Service}}{{end}} · {{ms .Duration}} · attempts {{.Attempts}}</span></summary>
+{{if .Failure}}<pre class="failure">{{.Failure}}</pre>{{end}}
+{{if .Variables}}
+<p><strong>Variables at failure</strong></p>
+<table><tr><th>name</th><th>value</th></tr>{{$vars := .Variables}}{{range keys .Variables}}<tr><td>{{.}}</td><td>{{index $vars .}}</td></tr>{{end}}</table>
+{{end}}
+{{range $i, $step := .Steps}}
+<details{{if failed $step.Status}} open{{end}}>
+<summary><span class="badge {{$step.Status}}">{{$step.Status}}</span> step {{inc $i}}{{if $step.Name}}: {{$step.Name}}{{end}} <span class="muted">{{ms $step.Duration}}</span></summary>
+{{if $step.Failure}}<pre class="failure">{{$step.Failure}}</pre>{{end}}
+{{with $step.Request}}
+<p><strong>Request</strong></p>
+<pre>{{.Method}} {{.URL}}{{$h := .Headers}}{{range keys .Headers}}
+{{.}}: {{index $h .}}{{end}}{{if .Body}}
+
+{{pretty .Body}}{{end}}</pre>
+{{end}}
+{{with $step.Command}}
+<p><strong>Command</strong> <span class="muted">exit code {{.ExitCode}}</span></p>
+<pre>$ {{.Command}}</pre>
+{{if .Stdout}}<p class="muted">stdout</p><pre>{{.Stdout}}</pre>{{end}}
+{{if .Stderr}}<p class="muted">stderr</p><pre>{{.Stderr}}</pre>{{end}}
+{{end}}
+{{with $step.Response}}
+<p><strong>Response</strong></p>
+<pre>HTTP {{.StatusCode}}{{if .Body}}
+
+{{pretty .Body}}{{end}}</pre>
+{{end}}
+{{if $step.Assertions}}
+<p><strong>Assertions</strong></p>
+<table><tr><th>status</th><th>target</th><th>attempts</th><th>query</th><th>details</th></tr>
+{{range $step.Assertions}}<tr><td><span class="badge {{.Status}}">{{.Status}}</span></td><td>{{.Target}}</td><td>{{.Attempts}}</td><td>{{if .Query}}<pre>{{json .Query}}</pre>{{end}}</td><td>{{if .Diff}}<pre class="failure">{{.Diff}}</pre>{{end}}</td></tr>
+{{end}}</table>
+{{end}}
+</details>
+{{end}}
+</details>
+{{end}}
+</details>
+{{end}}
+</body>
+</html>
+`
@@ -0,0 +1,227 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/go-test-framework/framework/result"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	ID        string          `xml:"id,attr"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+	SystemErr *junitText      `xml:"system-err,omitempty"`
+}
+
+type junitTestCase struct {
+	Name       string          `xml:"name,attr"`
+	Classname  string          `xml:"classname,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Failure    *junitMessage   `xml:"failure,omitempty"`
+	Error      *junitMessage   `xml:"error,omitempty"`
+	Skipped    *junitMessage   `xml:"skipped,omitempty"`
+	SystemOut  *junitText      `xml:"system-out,omitempty"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",cdata"`
+}
+
+// junitText keeps multi-line output readable by emitting it as CDATA.
+type junitText struct {
+	Text string `xml:",cdata"`
+}
+
+func text(s string) *junitText {
+	if s == "" {
+		return nil
+	}
+	return &junitText{Text: s}
+}
+
+// WriteJUnit renders suites as JUnit XML: one <testsuite> per suite and one
+// <testcase> per test, with attempts as properties and the captured HTTP
+// exchanges in <system-out>.
+func WriteJUnit(w io.Writer, suites []*result.SuiteResult) error {
+	doc := junitTestSuites{}
+	var total time.Duration
+	for _, s := range suites {
+		js := junitTestSuite{ID: s.ID, Name: suiteName(s), Time: seconds(s.Duration), SystemErr: text(s.Failure)}
+		if !s.StartedAt.IsZero() {
+			js.Timestamp = s.StartedAt.UTC().Format("2006-01-02T15:04:05")
+		}
+		for _, t := range s.Tests {
+			js.Cases = append(js.Cases, junitCase(s, t))
+			js.Tests++
+			switch t.Status {
+			case result.StatusFail:
+				js.Failures++
+			case result.StatusError:
+				js.Errors++
+			case result.StatusSkip:
+				js.Skipped++
+			}
+		}
+		doc.Tests += js.Tests
+		doc.Failures += js.Failures
+		doc.Errors += js.Errors
+		doc.Skipped += js.Skipped
+		total += s.Duration
+		doc.Suites = append(doc.Suites, js)
+	}
+	doc.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteJUnitFile writes the JUnit report to path.
+func WriteJUnitFile(path string, suites []*result.SuiteResult) error {
+	return writeFile(path, suites, WriteJUnit)
+}
+
+func junitCase(s *result.SuiteResult, t *result.TestResult) junitTestCase {
+	jc := junitTestCase{
+		Name:      t.Name,
+		Classname: s.ID,
+		Time:      seconds(t.Duration),
+		Properties: []junitProperty{
+			{Name: "type", Value: t.Type},
+			{Name: "attempts", Value: strconv.Itoa(t.Attempts)},
+			{Name: "retries", Value: strconv.Itoa(max(t.Attempts-1, 0))},
+		},
+		SystemOut: text(formatSteps(t.Steps)),
+	}
+	if t.Service != "" {
+		jc.Properties = append(jc.Properties, junitProperty{Name: "service", Value: t.Service})
+	}
+	switch t.Status {
+	case result.StatusFail:
+		jc.Failure = &junitMessage{Message: firstLine(t.Failure), Type: "AssertionError", Body: t.Failure}
+	case result.StatusError:
+		jc.Error = &junitMessage{Message: firstLine(t.Failure), Type: "Error", Body: t.Failure}
+	case result.StatusSkip:
+		jc.Skipped = &junitMessage{Message: "not run"}
+	}
+	return jc
+}
+
+// formatSteps renders steps as plain text with their HTTP exchanges and
+// assertion outcomes.
+func formatSteps(steps []result.StepResult) string {
+	var b strings.Builder
+	for i, step := range steps {
+		fmt.Fprintf(&b, "[step %d] %s: %s (%s)\n", i+1, step.Name, step.Status, step.Duration.Round(time.Millisecond))
+		if req := step.Request; req != nil {
+			fmt.Fprintf(&b, "> %s %s\n", req.Method, req.URL)
+			for _, k := range sortedKeys(req.Headers) {
+				fmt.Fprintf(&b, "> %s: %s\n", k, req.Headers[k])
+			}
+			if req.Body != "" {
+				fmt.Fprintf(&b, "> %s\n", req.Body)
+			}
+		}
+		if run := step.Command; run != nil {
+			fmt.Fprintf(&b, "$ %s\n", run.Command)
+			fmt.Fprintf(&b, "exit code %d\n", run.ExitCode)
+			if run.Stdout != "" {
+				fmt.Fprintf(&b, "stdout:\n%s\n", strings.TrimRight(run.Stdout, "\n"))
+			}
+			if run.Stderr != "" {
+				fmt.Fprintf(&b, "stderr:\n%s\n", strings.TrimRight(run.Stderr, "\n"))
+			}
+		}
+		if resp := step.Response; resp != nil {
+			fmt.Fprintf(&b, "< %d\n", resp.StatusCode)
+			if resp.Body != "" {
+				fmt.Fprintf(&b, "< %s\n", resp.Body)
+			}
+		}
+		for _, a := range step.Assertions {
+			fmt.Fprintf(&b, "  assert %s: %s (attempts=%d)\n", a.Target, a.Status, a.Attempts)
+			if a.Diff != "" {
+				fmt.Fprintf(&b, "    %s\n", a.Diff)
+			}
+		}
+		if step.Failure != "" {
+			fmt.Fprintf(&b, "  failure: %s\n", step.Failure)
+		}
+	}
+	return b.String()
+}
+
+func writeFile(path string, suites []*result.SuiteResult, write func(io.Writer, []*result.SuiteResult) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f, suites); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func suiteName(s *result.SuiteResult) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.ID
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,105 @@
+package result
+
+import "time"
+
+// Status is the outcome of a suite, test, step or assertion.
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip"
+	// StatusError marks problems outside the system under test, such as a
+	// missing client, a transport error or a timeout.
+	StatusError Status = "error"
+)
+
+// SuiteResult is the outcome of one TestSuite run.
+type SuiteResult struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name"`
+	Status    Status        `json:"status"`
+	StartedAt time.Time     `json:"startedAt"`
+	Duration  time.Duration `json:"duration"`
+	Tests     []*TestResult `json:"tests"`
+	Failure   string        `json:"failure,omitempty"`
+}
+
+// TestResult is the outcome of a TestDefinition or DeclarativeTest. Steps
+// belong to the last attempt; Variables is the execution context captured
+// when the test failed.
+type TestResult struct {
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	Service   string            `json:"service,omitempty"`
+	Status    Status            `json:"status"`
+	Attempts  int               `json:"attempts"`
+	StartedAt time.Time         `json:"startedAt"`
+	Duration  time.Duration     `json:"duration"`
+	Failure   string            `json:"failure,omitempty"`
+	Steps     []StepResult      `json:"steps,omitempty"`
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// StepResult is the outcome of a single declarative step.
+type StepResult struct {
+	Name       string            `json:"name"`
+	Status     Status            `json:"status"`
+	Duration   time.Duration     `json:"duration"`
+	Request    *HTTPRequest      `json:"request,omitempty"`
+	Response   *HTTPResponse     `json:"response,omitempty"`
+	Command    *CommandRun       `json:"command,omitempty"`
+	Assertions []AssertionResult `json:"assertions,omitempty"`
+	Failure    string            `json:"failure,omitempty"`
+}
+
+// HTTPRequest is the request a step sent after variable substitution.
+type HTTPRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// HTTPResponse is the raw response a step received.
+type HTTPResponse struct {
+	StatusCode int    `json:"statusCode"`
+	Body       string `json:"body,omitempty"`
+}
+
+// CommandRun is the captured outcome of a script step.
+type CommandRun struct {
+	Command  string `json:"command"`
+	ExitCode int    `json:"exitCode"`
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+}
+
+// AssertionResult records one response or database check. Diff holds the
+// mismatch description when the check failed.
+type AssertionResult struct {
+	Target   string         `json:"target"`
+	Status   Status         `json:"status"`
+	Attempts int            `json:"attempts"`
+	Query    map[string]any `json:"query,omitempty"`
+	Diff     string         `json:"diff,omitempty"`
+}
+
+// Counts tallies tests by status.
+func (s *SuiteResult) Counts() map[Status]int {
+	counts := map[Status]int{}
+	for _, t := range s.Tests {
+		counts[t.Status]++
+	}
+	return counts
+}
+
+// Passed reports whether every suite passed.
+func Passed(suites []*SuiteResult) bool {
+	for _, s := range suites {
+		if s.Status != StatusPass {
+			return false
+		}
+	}
+	return true
+}
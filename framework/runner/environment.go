@@ -0,0 +1,53 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/example/go-test-framework/framework/db/pool"
+	"github.com/example/go-test-framework/framework/declarative"
+	"github.com/example/go-test-framework/framework/env"
+	"github.com/example/go-test-framework/framework/suite"
+	"github.com/example/go-test-framework/framework/utils"
+)
+
+// provision brings up the suite's declared environment when a Provisioner is
+// set and returns a copy of the declarative executor with a client pool
+// for the suite, together with the endpoints and a cleanup func that closes
+// the pool and releases the environment. Without a declared environment
+// or connection templates the executor is shared unchanged.
+func (r *Runner) provision(ctx context.Context, ts *suite.TestSuite, log *utils.StructuredLogger) (*declarative.Executor, *env.Endpoints, func(), error) {
+	var endpoints *env.Endpoints
+	release := func() {}
+	if r.Provisioner != nil && !ts.Environment.IsEmpty() {
+		log.Info("provisioning environment", nil)
+		var err error
+		endpoints, err = r.Provisioner.Provision(ctx, ts.ID, ts.Environment)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("provision environment: %w", err)
+		}
+		release = func() {
+			if err := r.Provisioner.Release(context.WithoutCancel(ctx), ts.ID); err != nil {
+				log.Error("releasing environment failed", map[string]any{"error": err.Error()})
+			}
+		}
+		log.Info("environment ready", nil)
+	}
+	if ts.Environment.IsEmpty() && r.Databases == (pool.Templates{}) {
+		return r.DeclarativeExecutor, endpoints, release, nil
+	}
+
+	decl := &declarative.Executor{Logger: r.Logger}
+	if r.DeclarativeExecutor != nil {
+		shared := *r.DeclarativeExecutor
+		decl = &shared
+	}
+	decl.DB = pool.New(r.Databases, ts.Environment, endpoints)
+	cleanup := func() {
+		if err := decl.DB.Close(context.WithoutCancel(ctx)); err != nil {
+			log.Error("closing database clients failed", map[string]any{"error": err.Error()})
+		}
+		release()
+	}
+	return decl, endpoints, cleanup, nil
+}
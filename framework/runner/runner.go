@@ -3,48 +3,124 @@ package runner
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/example/go-test-framework/framework/db/pool"
 	"github.com/example/go-test-framework/framework/declarative"
+	"github.com/example/go-test-framework/framework/env"
 	"github.com/example/go-test-framework/framework/executor"
+	"github.com/example/go-test-framework/framework/result"
+	"github.com/example/go-test-framework/framework/script"
 	"github.com/example/go-test-framework/framework/suite"
 	"github.com/example/go-test-framework/framework/utils"
 )
 
 // Runner coordinates suite execution.
 type Runner struct {
-	TestExecutor        *executor.TestExecutor
+	Executors           *executor.Registry
 	DeclarativeExecutor *declarative.Executor
-	Logger              *utils.StructuredLogger
+	// Provisioner, when set, provisions each suite's Environment first.
+	Provisioner env.Provisioner
+	// Databases are connection templates used without a Provisioner.
+	Databases pool.Templates
+	Logger    *utils.StructuredLogger
+	// ContinueOnFailure runs every test and suite past failures.
+	ContinueOnFailure bool
+	// Concurrency caps parallel tests per suite; zero is unlimited.
+	Concurrency int
+	// SuiteConcurrency caps suites run side by side; zero is one.
+	SuiteConcurrency int
 }
 
-func New(testExec *executor.TestExecutor, decl *declarative.Executor) *Runner {
+func New(executors *executor.Registry, decl *declarative.Executor) *Runner {
 	logger := utils.NewLogger()
-	if testExec != nil && testExec.Logger == nil {
-		testExec.Logger = logger
-	}
 	if decl != nil && decl.Logger == nil {
 		decl.Logger = logger
 	}
-	return &Runner{TestExecutor: testExec, DeclarativeExecutor: decl, Logger: logger}
+	return &Runner{Executors: executors, DeclarativeExecutor: decl, Logger: logger}
 }
 
-func (r *Runner) RunAll(ctx context.Context, suites []*suite.TestSuite) error {
-	for _, ts := range suites {
-		if err := r.RunSuite(ctx, ts); err != nil {
-			return err
-		}
+// RunAll runs suites, SuiteConcurrency at a time, and returns their results
+// in input order. Unless ContinueOnFailure is set no new suite starts after a
+// failure and the suites that did not run are reported as skipped. Failures
+// of all suites that ran are joined into the returned error.
+func (r *Runner) RunAll(ctx context.Context, suites []*suite.TestSuite) ([]*result.SuiteResult, error) {
+	results := make([]*result.SuiteResult, len(suites))
+	errs := make([]error, len(suites))
+	workers := r.SuiteConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		wg      sync.WaitGroup
+		stopped atomic.Bool
+		jobs    = make(chan int)
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if stopped.Load() {
+					results[i] = skippedSuite(suites[i])
+					continue
+				}
+				res, err := r.RunSuite(ctx, suites[i])
+				results[i] = res
+				if err != nil {
+					errs[i] = fmt.Errorf("suite %s: %w", suites[i].ID, err)
+					if !r.ContinueOnFailure {
+						stopped.Store(true)
+					}
+				}
+			}
+		}()
 	}
-	return nil
+	for i := range suites {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results, errors.Join(errs...)
 }
 
-func (r *Runner) RunSuite(ctx context.Context, ts *suite.TestSuite) error {
-	if r.Logger == nil {
-		r.Logger = utils.NewLogger()
+// RunSuite runs a single suite. The returned result is always populated,
+// even when an error is returned.
+func (r *Runner) RunSuite(ctx context.Context, ts *suite.TestSuite) (*result.SuiteResult, error) {
+	logger := r.Logger
+	if logger == nil {
+		logger = utils.NewLogger()
 	}
-	log := r.Logger.With("suite", ts.ID)
+	log := logger.With("suite", ts.ID)
 	log.Info("starting suite", map[string]any{"services": ts.Services})
+	ctx = utils.ContextWithLogger(ctx, log)
+
+	res := skippedSuite(ts)
+	res.StartedAt = time.Now()
+
+	decl, endpoints, cleanup, err := r.provision(ctx, ts, log)
+	if err != nil {
+		res.Duration = time.Since(res.StartedAt)
+		res.Status, res.Failure = result.StatusFail, err.Error()
+		// The tests never ran; report them as errors so reports count them.
+		for _, tr := range res.Tests {
+			tr.Status, tr.Failure = result.StatusError, "suite setup failed: "+err.Error()
+		}
+		log.Error("suite failed", map[string]any{"error": err.Error()})
+		return res, err
+	}
+	defer cleanup()
+	scriptEnv := script.ConfigEnv(ts.Config)
+	if endpoints != nil {
+		for k, v := range endpoints.Env() {
+			scriptEnv[k] = v
+		}
+	}
+	ctx = script.ContextWithEnv(ctx, scriptEnv)
 
 	var cancel context.CancelFunc
 	if ts.Timeout > 0 {
@@ -54,86 +130,178 @@ func (r *Runner) RunSuite(ctx context.Context, ts *suite.TestSuite) error {
 
 	execCtx := utils.NewExecutionContext()
 
-	run := func(def suite.TestDefinition) error {
-		return r.runWithRetry(ctx, ts.Retries, func(attempt int) error {
-			log.Info("running test", map[string]any{"service": def.Service, "attempt": attempt + 1})
-			return r.TestExecutor.Run(ctx, def)
+	run := func(def suite.TestDefinition, tr *result.TestResult) error {
+		ex, err := r.lookupExecutor(def.Type)
+		if err != nil {
+			tr.Status, tr.Failure = result.StatusError, err.Error()
+			return err
+		}
+		return r.runWithRetry(ctx, ts.Retries, tr, func(attempt int) error {
+			log.Info("running test", map[string]any{"service": def.Service, "type": def.Type, "attempt": attempt + 1})
+			steps, err := ex.Run(ctx, ts, def, execCtx)
+			tr.Steps = steps
+			return err
 		})
 	}
 
-	runDeclarative := func(def declarative.DeclarativeTest) error {
-		return r.runWithRetry(ctx, ts.Retries, func(attempt int) error {
+	runDeclarative := func(def declarative.DeclarativeTest, tr *result.TestResult) error {
+		return r.runWithRetry(ctx, ts.Retries, tr, func(attempt int) error {
 			log.Info("running declarative test", map[string]any{"name": def.Name, "attempt": attempt + 1})
-			return r.DeclarativeExecutor.Run(ctx, def, execCtx)
+			steps, err := decl.Run(ctx, def, execCtx)
+			tr.Steps = steps
+			if err != nil {
+				tr.Variables = execCtx.Snapshot()
+			}
+			return err
 		})
 	}
 
+	runTest := func(idx int) error {
+		var err error
+		if idx < len(ts.Tests) {
+			err = run(ts.Tests[idx], res.Tests[idx])
+		} else {
+			err = runDeclarative(ts.DeclarativeTests[idx-len(ts.Tests)], res.Tests[idx])
+		}
+		if err != nil {
+			return fmt.Errorf("test %q: %w", res.Tests[idx].Name, err)
+		}
+		return nil
+	}
+
 	switch ts.ExecutionType {
 	case suite.ExecutionTypeParallel:
-		return r.runParallel(len(ts.Tests)+len(ts.DeclarativeTests), func(idx int) error {
-			if idx < len(ts.Tests) {
-				return run(ts.Tests[idx])
-			}
-			return runDeclarative(ts.DeclarativeTests[idx-len(ts.Tests)])
-		})
+		err = r.runParallel(len(res.Tests), r.parallelLimit(ts), runTest)
 	default:
-		for _, testDef := range ts.Tests {
-			if err := run(testDef); err != nil {
-				return err
+		continueOnFailure := r.ContinueOnFailure || ts.ContinueOnFailure
+		var errs []error
+		for idx := range res.Tests {
+			if testErr := runTest(idx); testErr != nil {
+				errs = append(errs, testErr)
+				if !continueOnFailure {
+					break
+				}
 			}
 		}
-		for _, decl := range ts.DeclarativeTests {
-			if err := runDeclarative(decl); err != nil {
-				return err
-			}
+		err = errors.Join(errs...)
+	}
+
+	res.Duration = time.Since(res.StartedAt)
+	res.Status = result.StatusPass
+	for _, tr := range res.Tests {
+		if tr.Status == result.StatusFail || tr.Status == result.StatusError {
+			res.Status = result.StatusFail
 		}
-		log.Info("suite finished", nil)
-		return nil
 	}
+	if err != nil {
+		res.Status = result.StatusFail
+		res.Failure = err.Error()
+		log.Error("suite failed", map[string]any{"error": err.Error()})
+		return res, err
+	}
+	log.Info("suite finished", nil)
+	return res, nil
 }
 
-func (r *Runner) runWithRetry(ctx context.Context, retries int, fn func(attempt int) error) error {
+func (r *Runner) lookupExecutor(testType string) (executor.Executor, error) {
+	if r.Executors == nil {
+		return nil, errors.New("no test executors configured")
+	}
+	return r.Executors.Lookup(testType)
+}
+
+// skippedSuite builds a result with every test marked as skipped; RunSuite
+// overwrites entries as tests run.
+func skippedSuite(ts *suite.TestSuite) *result.SuiteResult {
+	res := &result.SuiteResult{ID: ts.ID, Name: ts.Name, Status: result.StatusSkip}
+	for _, def := range ts.Tests {
+		res.Tests = append(res.Tests, &result.TestResult{Name: def.Service, Type: def.Type, Service: def.Service, Status: result.StatusSkip})
+	}
+	for _, def := range ts.DeclarativeTests {
+		res.Tests = append(res.Tests, &result.TestResult{Name: def.Name, Type: "declarative", Status: result.StatusSkip})
+	}
+	return res
+}
+
+// runWithRetry runs fn until it succeeds or retries are exhausted, recording
+// attempts, timing and the final status on tr.
+func (r *Runner) runWithRetry(ctx context.Context, retries int, tr *result.TestResult, fn func(attempt int) error) error {
+	tr.StartedAt = time.Now()
+	defer func() { tr.Duration = time.Since(tr.StartedAt) }()
+
 	var lastErr error
 	attempts := retries + 1
 	if attempts < 1 {
 		attempts = 1
 	}
 	for attempt := 0; attempt < attempts; attempt++ {
+		tr.Attempts = attempt + 1
 		if err := fn(attempt); err != nil {
 			lastErr = err
+			if attempt+1 == attempts {
+				break
+			}
 			select {
 			case <-time.After(time.Duration(attempt+1) * 500 * time.Millisecond):
 			case <-ctx.Done():
-				return ctx.Err()
+				lastErr = ctx.Err()
+				tr.Status, tr.Failure = result.StatusError, lastErr.Error()
+				return lastErr
 			}
 			continue
 		}
+		tr.Status, tr.Failure, tr.Variables = result.StatusPass, "", nil
 		return nil
 	}
 	if lastErr == nil {
 		lastErr = errors.New("unknown retry failure")
 	}
+	tr.Status, tr.Failure = testStatus(tr, lastErr), lastErr.Error()
 	return lastErr
 }
 
-func (r *Runner) runParallel(total int, fn func(idx int) error) error {
+// testStatus derives a test status from its last attempt: steps decide
+// between fail and error when present.
+func testStatus(tr *result.TestResult, err error) result.Status {
+	for _, step := range tr.Steps {
+		if step.Status == result.StatusFail || step.Status == result.StatusError {
+			return step.Status
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return result.StatusError
+	}
+	return result.StatusFail
+}
+
+// parallelLimit combines the runner-wide and per-suite caps; zero means
+// unlimited.
+func (r *Runner) parallelLimit(ts *suite.TestSuite) int {
+	limit := r.Concurrency
+	if ts.MaxParallel > 0 && (limit <= 0 || ts.MaxParallel < limit) {
+		limit = ts.MaxParallel
+	}
+	return limit
+}
+
+// runParallel runs tests concurrently, at most limit at a time when limit is
+// positive, and joins their errors in test order.
+func (r *Runner) runParallel(total, limit int, fn func(idx int) error) error {
+	if limit <= 0 || limit > total {
+		limit = total
+	}
 	var wg sync.WaitGroup
-	errCh := make(chan error, total)
+	errs := make([]error, total)
+	sem := make(chan struct{}, limit)
 	for i := 0; i < total; i++ {
 		wg.Add(1)
+		sem <- struct{}{}
 		go func(idx int) {
 			defer wg.Done()
-			if err := fn(idx); err != nil {
-				errCh <- err
-			}
+			defer func() { <-sem }()
+			errs[idx] = fn(idx)
 		}(i)
 	}
 	wg.Wait()
-	close(errCh)
-	for err := range errCh {
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	return errors.Join(errs...)
 }
@@ -0,0 +1,340 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/example/go-test-framework/framework/executor"
+	"github.com/example/go-test-framework/framework/result"
+	"github.com/example/go-test-framework/framework/suite"
+	"github.com/example/go-test-framework/framework/utils"
+)
+
+// stub is a test executor that fails the services listed in fail and records
+// the order in which services ran.
+type stub struct {
+	mu   sync.Mutex
+	fail map[string]bool
+	ran  []string
+}
+
+func (s *stub) Run(_ context.Context, ts *suite.TestSuite, def suite.TestDefinition, _ *utils.ExecutionContext) ([]result.StepResult, error) {
+	s.mu.Lock()
+	s.ran = append(s.ran, ts.ID+"/"+def.Service)
+	s.mu.Unlock()
+	if s.fail[def.Service] {
+		return []result.StepResult{{Name: def.Service, Status: result.StatusFail, Failure: "boom"}}, errors.New(def.Service + " failed")
+	}
+	return []result.StepResult{{Name: def.Service, Status: result.StatusPass}}, nil
+}
+
+func newRunner(ex executor.Executor) *Runner {
+	reg := executor.NewRegistry()
+	reg.Register("stub", ex)
+	return New(reg, nil)
+}
+
+func stubSuite(id string, continueOnFailure bool, services ...string) *suite.TestSuite {
+	ts := &suite.TestSuite{ID: id, ContinueOnFailure: continueOnFailure}
+	for _, s := range services {
+		ts.Tests = append(ts.Tests, suite.TestDefinition{Service: s, Type: "stub"})
+	}
+	return ts
+}
+
+func statuses(res *result.SuiteResult) string {
+	var out []string
+	for _, tr := range res.Tests {
+		out = append(out, tr.Name+"="+string(tr.Status))
+	}
+	return strings.Join(out, " ")
+}
+
+func TestRunSuiteFailFast(t *testing.T) {
+	tests := []struct {
+		name           string
+		runnerContinue bool
+		suiteContinue  bool
+		wantRan        string
+		wantStatuses   string
+		wantErr        string
+	}{
+		{
+			name:         "stops at the first failure",
+			wantRan:      "s/a s/b",
+			wantStatuses: "a=pass b=fail c=skip d=skip",
+			wantErr:      `test "b": b failed`,
+		},
+		{
+			name:           "runner continues on failure",
+			runnerContinue: true,
+			wantRan:        "s/a s/b s/c s/d",
+			wantStatuses:   "a=pass b=fail c=pass d=fail",
+			wantErr:        "test \"b\": b failed\ntest \"d\": d failed",
+		},
+		{
+			name:          "suite continues on failure",
+			suiteContinue: true,
+			wantRan:       "s/a s/b s/c s/d",
+			wantStatuses:  "a=pass b=fail c=pass d=fail",
+			wantErr:       "test \"b\": b failed\ntest \"d\": d failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex := &stub{fail: map[string]bool{"b": true, "d": true}}
+			r := newRunner(ex)
+			r.ContinueOnFailure = tt.runnerContinue
+			res, err := r.RunSuite(context.Background(), stubSuite("s", tt.suiteContinue, "a", "b", "c", "d"))
+			if got := strings.Join(ex.ran, " "); got != tt.wantRan {
+				t.Errorf("ran %s, want %s", got, tt.wantRan)
+			}
+			if got := statuses(res); got != tt.wantStatuses {
+				t.Errorf("statuses %s, want %s", got, tt.wantStatuses)
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("error %q, want %q", err, tt.wantErr)
+			}
+			if res.Status != result.StatusFail || res.Failure != tt.wantErr {
+				t.Errorf("suite %s %q", res.Status, res.Failure)
+			}
+		})
+	}
+}
+
+func TestRunAll(t *testing.T) {
+	tests := []struct {
+		name           string
+		continueOnFail bool
+		wantRan        string
+		wantSuites     []result.Status
+		wantErr        []string
+	}{
+		{
+			name:       "stops after a failing suite",
+			wantRan:    "one/a two/b",
+			wantSuites: []result.Status{result.StatusPass, result.StatusFail, result.StatusSkip, result.StatusSkip},
+			wantErr:    []string{`suite two: test "b": b failed`},
+		},
+		{
+			name:           "continues past failing suites",
+			continueOnFail: true,
+			wantRan:        "one/a two/b three/c four/d",
+			wantSuites:     []result.Status{result.StatusPass, result.StatusFail, result.StatusPass, result.StatusFail},
+			wantErr:        []string{`suite two: test "b": b failed`, `suite four: test "d": d failed`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex := &stub{fail: map[string]bool{"b": true, "d": true}}
+			r := newRunner(ex)
+			r.ContinueOnFailure = tt.continueOnFail
+			suites := []*suite.TestSuite{
+				stubSuite("one", false, "a"),
+				stubSuite("two", false, "b"),
+				stubSuite("three", false, "c"),
+				stubSuite("four", false, "d"),
+			}
+			results, err := r.RunAll(context.Background(), suites)
+			if got := strings.Join(ex.ran, " "); got != tt.wantRan {
+				t.Errorf("ran %s, want %s", got, tt.wantRan)
+			}
+			if len(results) != len(suites) {
+				t.Fatalf("%d results for %d suites", len(results), len(suites))
+			}
+			for i, res := range results {
+				if res.ID != suites[i].ID || res.Status != tt.wantSuites[i] {
+					t.Errorf("result %d = %s %s, want %s %s", i, res.ID, res.Status, suites[i].ID, tt.wantSuites[i])
+				}
+				if res.Status == result.StatusSkip && statuses(res) != res.Tests[0].Name+"=skip" {
+					t.Errorf("skipped suite %s has tests %s", res.ID, statuses(res))
+				}
+			}
+			if err == nil || err.Error() != strings.Join(tt.wantErr, "\n") {
+				t.Errorf("error %q, want %q", err, strings.Join(tt.wantErr, "\n"))
+			}
+		})
+	}
+}
+
+func TestRunAllPasses(t *testing.T) {
+	r := newRunner(&stub{})
+	results, err := r.RunAll(context.Background(), []*suite.TestSuite{stubSuite("one", false, "a", "b")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Status != result.StatusPass || statuses(results[0]) != "a=pass b=pass" {
+		t.Fatalf("result %s: %s", results[0].Status, statuses(results[0]))
+	}
+}
+
+func TestRunSuiteUnknownType(t *testing.T) {
+	r := newRunner(&stub{})
+	ts := &suite.TestSuite{ID: "s", Tests: []suite.TestDefinition{{Service: "a", Type: "k6"}}}
+	res, err := r.RunSuite(context.Background(), ts)
+	if err == nil || !strings.Contains(err.Error(), "k6") {
+		t.Fatalf("error %v", err)
+	}
+	if res.Tests[0].Status != result.StatusError {
+		t.Fatalf("test status %s, want %s", res.Tests[0].Status, result.StatusError)
+	}
+}
+
+func TestRunSuiteRetries(t *testing.T) {
+	attempts := 0
+	r := newRunner(executor.ExecutorFunc(func(context.Context, *suite.TestSuite, suite.TestDefinition, *utils.ExecutionContext) ([]result.StepResult, error) {
+		attempts++
+		if attempts < 2 {
+			return nil, errors.New("flaky")
+		}
+		return nil, nil
+	}))
+	ts := stubSuite("s", false, "a")
+	ts.Retries = 2
+	res, err := r.RunSuite(context.Background(), ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr := res.Tests[0]; tr.Status != result.StatusPass || tr.Attempts != 2 {
+		t.Fatalf("test %s after %d attempts", tr.Status, tr.Attempts)
+	}
+}
+
+// gauge is a test executor that records how many tests run at once.
+type gauge struct {
+	mu         sync.Mutex
+	running    int
+	peak       int
+	perSuite   map[string]int
+	suitePeaks map[string]int
+}
+
+func (g *gauge) Run(_ context.Context, ts *suite.TestSuite, _ suite.TestDefinition, _ *utils.ExecutionContext) ([]result.StepResult, error) {
+	g.mu.Lock()
+	if g.perSuite == nil {
+		g.perSuite, g.suitePeaks = map[string]int{}, map[string]int{}
+	}
+	g.running++
+	g.perSuite[ts.ID]++
+	g.peak = max(g.peak, g.running)
+	g.suitePeaks[ts.ID] = max(g.suitePeaks[ts.ID], g.perSuite[ts.ID])
+	g.mu.Unlock()
+
+	time.Sleep(30 * time.Millisecond)
+
+	g.mu.Lock()
+	g.running--
+	g.perSuite[ts.ID]--
+	g.mu.Unlock()
+	return nil, nil
+}
+
+func TestParallelLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		maxParallel int
+		want        int
+	}{
+		{name: "unlimited", want: 6},
+		{name: "runner cap", concurrency: 2, want: 2},
+		{name: "suite cap", maxParallel: 3, want: 3},
+		{name: "lower suite cap wins", concurrency: 4, maxParallel: 3, want: 3},
+		{name: "lower runner cap wins", concurrency: 2, maxParallel: 3, want: 2},
+		{name: "cap above test count", concurrency: 10, want: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &gauge{}
+			r := newRunner(g)
+			r.Concurrency = tt.concurrency
+			ts := stubSuite("s", false, "a", "b", "c", "d", "e", "f")
+			ts.ExecutionType = suite.ExecutionTypeParallel
+			ts.MaxParallel = tt.maxParallel
+			if _, err := r.RunSuite(context.Background(), ts); err != nil {
+				t.Fatal(err)
+			}
+			if g.peak != tt.want {
+				t.Fatalf("peak concurrency %d, want %d", g.peak, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuiteConcurrency(t *testing.T) {
+	tests := []struct {
+		name             string
+		suiteConcurrency int
+		concurrency      int
+		wantPeak         int
+		wantSuitePeak    int
+	}{
+		{name: "one suite at a time by default", wantPeak: 2, wantSuitePeak: 2},
+		{name: "suites side by side", suiteConcurrency: 3, wantPeak: 6, wantSuitePeak: 2},
+		{name: "test cap applies per suite", suiteConcurrency: 2, concurrency: 1, wantPeak: 2, wantSuitePeak: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &gauge{}
+			r := newRunner(g)
+			r.SuiteConcurrency = tt.suiteConcurrency
+			r.Concurrency = tt.concurrency
+			var suites []*suite.TestSuite
+			for _, id := range []string{"one", "two", "three"} {
+				ts := stubSuite(id, false, "a", "b")
+				ts.ExecutionType = suite.ExecutionTypeParallel
+				suites = append(suites, ts)
+			}
+			if _, err := r.RunAll(context.Background(), suites); err != nil {
+				t.Fatal(err)
+			}
+			if g.peak != tt.wantPeak {
+				t.Errorf("peak concurrency %d, want %d", g.peak, tt.wantPeak)
+			}
+			for id, peak := range g.suitePeaks {
+				if peak != tt.wantSuitePeak {
+					t.Errorf("suite %s peak %d, want %d", id, peak, tt.wantSuitePeak)
+				}
+			}
+		})
+	}
+}
+
+func TestSuitesDoNotShareVariables(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		seen = map[string]string{}
+	)
+	r := newRunner(executor.ExecutorFunc(func(_ context.Context, ts *suite.TestSuite, def suite.TestDefinition, execCtx *utils.ExecutionContext) ([]result.StepResult, error) {
+		switch def.Service {
+		case "set":
+			execCtx.Set("owner", ts.ID)
+			time.Sleep(20 * time.Millisecond)
+		case "get":
+			owner, _ := execCtx.Get("owner")
+			mu.Lock()
+			seen[ts.ID] = owner
+			mu.Unlock()
+		}
+		return nil, nil
+	}))
+	r.SuiteConcurrency = 3
+	suites := []*suite.TestSuite{
+		stubSuite("one", false, "set", "get"),
+		stubSuite("two", false, "set", "get"),
+		stubSuite("three", false, "get"),
+	}
+	if _, err := r.RunAll(context.Background(), suites); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"one": "one", "two": "two", "three": ""}
+	for id, owner := range want {
+		if seen[id] != owner {
+			t.Errorf("suite %s saw owner %q, want %q", id, seen[id], owner)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package script
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/example/go-test-framework/framework/utils"
+)
+
+// Spec describes a command or inline script to execute together with the
+// expectations on its outcome. Command is an argv list; Script is passed to
+// Shell (default "sh") with -c. `${var}` placeholders are substituted in
+// Command and Env values; Script is left to the shell, which reads the same
+// variables from its environment.
+type Spec struct {
+	Command  []string          `json:"command"`
+	Script   string            `json:"script"`
+	Shell    string            `json:"shell"`
+	Dir      string            `json:"dir"`
+	Env      map[string]string `json:"env"`
+	Timeout  time.Duration     `json:"timeout"`
+	ExitCode *int              `json:"exitCode"`
+	Stdout   []string          `json:"stdout"`
+	Stderr   []string          `json:"stderr"`
+	Extract  map[string]string `json:"extract"`
+}
+
+// Output is what a finished command produced.
+type Output struct {
+	Command  string
+	ExitCode int
+	Stdout   string
+	Stderr   string
+	Duration time.Duration
+}
+
+// Run executes spec in baseDir (Spec.Dir is resolved against it). The
+// process inherits the current environment plus env and Spec.Env, and is
+// killed when ctx or Spec.Timeout expires. A non-zero exit code is not an
+// error here; use Check for expectations.
+func Run(ctx context.Context, spec Spec, baseDir string, env map[string]string) (*Output, error) {
+	argv, err := spec.argv(env)
+	if err != nil {
+		return nil, err
+	}
+	if spec.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, spec.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = baseDir
+	if spec.Dir != "" {
+		cmd.Dir = spec.Dir
+		if !filepath.IsAbs(spec.Dir) && baseDir != "" {
+			cmd.Dir = filepath.Join(baseDir, spec.Dir)
+		}
+	}
+	cmd.Env = append(os.Environ(), envList(env)...)
+	for _, k := range sortedKeys(spec.Env) {
+		cmd.Env = append(cmd.Env, k+"="+utils.Substitute(spec.Env[k], env).(string))
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	started := time.Now()
+	runErr := cmd.Run()
+	out := &Output{
+		Command:  strings.Join(argv, " "),
+		ExitCode: cmd.ProcessState.ExitCode(),
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Duration: time.Since(started),
+	}
+	if ctx.Err() != nil {
+		return out, fmt.Errorf("command %q: %w", out.Command, ctx.Err())
+	}
+	var exitErr *exec.ExitError
+	if runErr != nil && !errors.As(runErr, &exitErr) {
+		return out, fmt.Errorf("command %q: %w", out.Command, runErr)
+	}
+	return out, nil
+}
+
+type envKey struct{}
+
+// ContextWithEnv attaches base environment variables (typically ConfigEnv of
+// the running suite) that callers merge under their own variables.
+func ContextWithEnv(ctx context.Context, env map[string]string) context.Context {
+	return context.WithValue(ctx, envKey{}, env)
+}
+
+// Env returns the variables attached by ContextWithEnv overlaid with vars.
+func Env(ctx context.Context, vars map[string]string) map[string]string {
+	env := map[string]string{}
+	if base, ok := ctx.Value(envKey{}).(map[string]string); ok {
+		for k, v := range base {
+			env[k] = v
+		}
+	}
+	for k, v := range vars {
+		env[k] = v
+	}
+	return env
+}
+
+// ConfigEnv converts the scalar values of a suite Config into environment
+// variables; nested maps and lists are skipped.
+func ConfigEnv(config map[string]any) map[string]string {
+	env := map[string]string{}
+	for k, v := range config {
+		switch v.(type) {
+		case string, bool, int, int64, float64:
+			env[k] = fmt.Sprint(v)
+		}
+	}
+	return env
+}
+
+func (s Spec) argv(vars map[string]string) ([]string, error) {
+	switch {
+	case len(s.Command) > 0 && s.Script != "":
+		return nil, errors.New("script spec sets both command and script")
+	case len(s.Command) > 0:
+		argv := make([]string, len(s.Command))
+		for i, arg := range s.Command {
+			argv[i] = utils.Substitute(arg, vars).(string)
+		}
+		return argv, nil
+	case s.Script != "":
+		shell := s.Shell
+		if shell == "" {
+			shell = "sh"
+		}
+		return []string{shell, "-c", s.Script}, nil
+	}
+	return nil, errors.New("script spec needs a command or script")
+}
+
+// Check verifies the exit code (0 unless ExitCode is set) and that every
+// Stdout/Stderr pattern matches.
+func (s Spec) Check(out *Output) error {
+	want := 0
+	if s.ExitCode != nil {
+		want = *s.ExitCode
+	}
+	if out.ExitCode != want {
+		return fmt.Errorf("exit code %d, want %d\nstderr: %s", out.ExitCode, want, strings.TrimSpace(out.Stderr))
+	}
+	if err := matchAll("stdout", s.Stdout, out.Stdout); err != nil {
+		return err
+	}
+	return matchAll("stderr", s.Stderr, out.Stderr)
+}
+
+// ExtractVars applies each Extract pattern to stdout. The first capture group
+// is used when present, otherwise the whole match.
+func (s Spec) ExtractVars(out *Output) (map[string]string, error) {
+	vars := map[string]string{}
+	for _, name := range sortedKeys(s.Extract) {
+		re, err := regexp.Compile(s.Extract[name])
+		if err != nil {
+			return nil, fmt.Errorf("extract %s: %w", name, err)
+		}
+		m := re.FindStringSubmatch(out.Stdout)
+		if m == nil {
+			return nil, fmt.Errorf("extract %s: /%s/ did not match stdout", name, s.Extract[name])
+		}
+		if len(m) > 1 {
+			vars[name] = m[1]
+		} else {
+			vars[name] = m[0]
+		}
+	}
+	return vars, nil
+}
+
+func matchAll(stream string, patterns []string, text string) error {
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("%s pattern: %w", stream, err)
+		}
+		if !re.MatchString(text) {
+			return fmt.Errorf("%s does not match /%s/\n%s: %s", stream, pattern, stream, strings.TrimSpace(text))
+		}
+	}
+	return nil
+}
+
+func envList(env map[string]string) []string {
+	out := make([]string, 0, len(env))
+	for _, k := range sortedKeys(env) {
+		out = append(out, k+"="+env[k])
+	}
+	return out
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
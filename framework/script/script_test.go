@@ -0,0 +1,32 @@
+package script
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRunScriptReadsVariablesFromEnvironment(t *testing.T) {
+	vars := map[string]string{"bonusId": "b-1", "name": "x'; echo pwned; '$(echo pwned)"}
+	tests := []struct {
+		name string
+		spec Spec
+		want string
+	}{
+		{name: "braced variable", spec: Spec{Script: `printf %s "${bonusId}"`}, want: "b-1"},
+		{name: "plain variable", spec: Spec{Script: `printf %s "$bonusId"`}, want: "b-1"},
+		{name: "value is not evaluated", spec: Spec{Script: `printf %s "$name"`}, want: vars["name"]},
+		{name: "command arguments are substituted", spec: Spec{Command: []string{"printf", "%s", "${name}"}}, want: vars["name"]},
+		{name: "env values are substituted", spec: Spec{Script: `printf %s "$BONUS"`, Env: map[string]string{"BONUS": "id=${bonusId}"}}, want: "id=b-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Run(context.Background(), tt.spec, t.TempDir(), vars)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.ExitCode != 0 || out.Stdout != tt.want {
+				t.Fatalf("exit %d, stdout %q, want %q (stderr %q)", out.ExitCode, out.Stdout, tt.want, out.Stderr)
+			}
+		})
+	}
+}
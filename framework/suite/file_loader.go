@@ -0,0 +1,270 @@
+package suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	suiteFileExtensions = map[string]bool{".yaml": true, ".yml": true, ".json": true}
+	durationType        = reflect.TypeOf(time.Duration(0))
+	yamlLinePattern     = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+)
+
+// LoadSuiteFiles parses every *.yaml, *.yml and *.json file in dir as a
+// TestSuite document. Files are read in name order and all parse errors are
+// reported together.
+func LoadSuiteFiles(dir string) ([]*TestSuite, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		suites []*TestSuite
+		errs   []error
+	)
+	for _, entry := range entries {
+		if entry.IsDir() || !suiteFileExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+			continue
+		}
+		ts, err := ParseSuiteFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		suites = append(suites, ts)
+	}
+	return suites, errors.Join(errs...)
+}
+
+// ParseSuiteFile reads a single YAML or JSON suite document. JSON is parsed
+// through the YAML decoder as well, so both formats share line-aware errors
+// and human-friendly durations such as "500ms" or "10m".
+func ParseSuiteFile(path string) (*TestSuite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ts, err := decodeSuite(data)
+	if err != nil {
+		var lineErr *LineError
+		if errors.As(err, &lineErr) {
+			lineErr.File = path
+			return nil, lineErr
+		}
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return ts, nil
+}
+
+// LineError points at the location in a suite file that failed to decode.
+type LineError struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+func decodeSuite(data []byte) (*TestSuite, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		if m := yamlLinePattern.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			return nil, &LineError{Line: line, Msg: m[2]}
+		}
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, errors.New("empty suite document")
+	}
+
+	conv := &nodeConverter{}
+	if err := conv.encode(doc.Content[0], reflect.TypeOf(TestSuite{})); err != nil {
+		return nil, err
+	}
+
+	var ts TestSuite
+	if err := json.Unmarshal(conv.buf.Bytes(), &ts); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, &LineError{Line: conv.lineAt(typeErr.Offset), Msg: strings.TrimPrefix(typeErr.Error(), "json: ")}
+		}
+		return nil, err
+	}
+	return &ts, nil
+}
+
+type offsetMark struct {
+	offset int64
+	line   int
+}
+
+// nodeConverter re-encodes a YAML node tree as JSON, guided by the Go type it
+// will be decoded into. It parses duration strings, rejects unknown struct
+// fields and remembers which source line produced each JSON value so decode
+// errors can be mapped back to the file.
+type nodeConverter struct {
+	buf   bytes.Buffer
+	marks []offsetMark
+}
+
+func (c *nodeConverter) lineAt(offset int64) int {
+	idx := sort.Search(len(c.marks), func(i int) bool { return c.marks[i].offset >= offset })
+	if idx > 0 {
+		idx--
+	}
+	if idx < len(c.marks) {
+		return c.marks[idx].line
+	}
+	return 0
+}
+
+func (c *nodeConverter) encode(n *yaml.Node, t reflect.Type) error {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t != nil && t.Kind() == reflect.Interface {
+		t = nil
+	}
+	c.marks = append(c.marks, offsetMark{offset: int64(c.buf.Len()), line: n.Line})
+
+	switch n.Kind {
+	case yaml.AliasNode:
+		return c.encode(n.Alias, t)
+	case yaml.MappingNode:
+		return c.encodeMapping(n, t)
+	case yaml.SequenceNode:
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+		c.buf.WriteByte('[')
+		for i, item := range n.Content {
+			if i > 0 {
+				c.buf.WriteByte(',')
+			}
+			if err := c.encode(item, elem); err != nil {
+				return err
+			}
+		}
+		c.buf.WriteByte(']')
+		return nil
+	case yaml.ScalarNode:
+		return c.encodeScalar(n, t)
+	default:
+		return &LineError{Line: n.Line, Msg: "unsupported yaml node"}
+	}
+}
+
+func (c *nodeConverter) encodeMapping(n *yaml.Node, t reflect.Type) error {
+	var fields map[string]reflect.Type
+	var elem reflect.Type
+	if t != nil {
+		switch t.Kind() {
+		case reflect.Struct:
+			fields = jsonFields(t)
+		case reflect.Map:
+			elem = t.Elem()
+		}
+	}
+
+	c.buf.WriteByte('{')
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if key.Kind != yaml.ScalarNode {
+			return &LineError{Line: key.Line, Msg: "mapping keys must be scalars"}
+		}
+		valueType := elem
+		if fields != nil {
+			ft, ok := fields[strings.ToLower(key.Value)]
+			if !ok {
+				return &LineError{Line: key.Line, Msg: fmt.Sprintf("unknown field %q in %s", key.Value, t.Name())}
+			}
+			valueType = ft
+		}
+		if i > 0 {
+			c.buf.WriteByte(',')
+		}
+		keyJSON, _ := json.Marshal(key.Value)
+		c.buf.Write(keyJSON)
+		c.buf.WriteByte(':')
+		if err := c.encode(value, valueType); err != nil {
+			return err
+		}
+	}
+	c.buf.WriteByte('}')
+	return nil
+}
+
+func (c *nodeConverter) encodeScalar(n *yaml.Node, t reflect.Type) error {
+	tag := n.ShortTag()
+	if tag == "!!null" {
+		c.buf.WriteString("null")
+		return nil
+	}
+	if t == durationType && tag == "!!str" {
+		d, err := time.ParseDuration(n.Value)
+		if err != nil {
+			return &LineError{Line: n.Line, Msg: fmt.Sprintf("invalid duration %q", n.Value)}
+		}
+		c.buf.WriteString(strconv.FormatInt(int64(d), 10))
+		return nil
+	}
+	// Keep the literal text for string fields so `version: 14` or
+	// `version: 6.0` do not turn into numbers.
+	if tag == "!!str" || (t != nil && t.Kind() == reflect.String) {
+		data, _ := json.Marshal(n.Value)
+		c.buf.Write(data)
+		return nil
+	}
+
+	var value any
+	if err := n.Decode(&value); err != nil {
+		return &LineError{Line: n.Line, Msg: err.Error()}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return &LineError{Line: n.Line, Msg: err.Error()}
+	}
+	c.buf.Write(data)
+	return nil
+}
+
+// jsonFields maps lower-cased json names to field types, mirroring the
+// case-insensitive matching encoding/json applies when decoding.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		fields[strings.ToLower(name)] = f.Type
+	}
+	return fields
+}
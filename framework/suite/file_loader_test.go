@@ -0,0 +1,191 @@
+package suite
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeSuite(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseSuiteFileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		wantLine int
+		wantMsg  string
+	}{
+		{
+			name:     "yaml syntax",
+			file:     "syntax.yaml",
+			content:  "id: broken\nservices:\n  - a\n - b\n",
+			wantLine: 3,
+			wantMsg:  "did not find expected key",
+		},
+		{
+			name:     "unknown suite field",
+			file:     "unknown.yaml",
+			content:  "id: x\nname: X\nretry: 2\n",
+			wantLine: 3,
+			wantMsg:  `unknown field "retry" in TestSuite`,
+		},
+		{
+			name: "unknown nested field",
+			file: "nested.yaml",
+			content: `id: x
+declarativeTests:
+  - name: t
+    action:
+      service: bonus-service
+      path: /api
+`,
+			wantLine: 6,
+			wantMsg:  `unknown field "path" in Action`,
+		},
+		{
+			name: "invalid duration",
+			file: "duration.yaml",
+			content: `id: x
+declarativeTests:
+  - name: t
+    assertions:
+      - database: postgres
+        eventually:
+          timeout: soon
+`,
+			wantLine: 7,
+			wantMsg:  `invalid duration "soon"`,
+		},
+		{
+			name:     "wrong type",
+			file:     "type.yaml",
+			content:  "id: x\nname: X\nretries: many\n",
+			wantLine: 3,
+			wantMsg:  "cannot unmarshal string into Go struct field TestSuite.retries of type int",
+		},
+		{
+			name:     "wrong type in list",
+			file:     "list.yaml",
+			content:  "id: x\nservices:\n  - a\n  - [b]\n",
+			wantLine: 4,
+			wantMsg:  "cannot unmarshal array",
+		},
+		{
+			name:     "json unknown field",
+			file:     "suite.json",
+			content:  "{\n  \"id\": \"x\",\n  \"timeOut\": \"5m\",\n  \"bogus\": true\n}\n",
+			wantLine: 4,
+			wantMsg:  `unknown field "bogus" in TestSuite`,
+		},
+		{
+			name:     "json syntax",
+			file:     "broken.json",
+			content:  "{\n  \"id\": \"x\",\n  \"name\": \"X\"\n  \"retries\": 1\n}\n",
+			wantLine: 3,
+			wantMsg:  "did not find expected ',' or '}'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeSuite(t, t.TempDir(), tt.file, tt.content)
+			_, err := ParseSuiteFile(path)
+			var lineErr *LineError
+			if !errors.As(err, &lineErr) {
+				t.Fatalf("error %v (%T), want *LineError", err, err)
+			}
+			if lineErr.File != path || lineErr.Line != tt.wantLine {
+				t.Fatalf("error at %s:%d, want %s:%d (%v)", lineErr.File, lineErr.Line, path, tt.wantLine, err)
+			}
+			if !strings.Contains(lineErr.Msg, tt.wantMsg) {
+				t.Fatalf("message %q, want it to contain %q", lineErr.Msg, tt.wantMsg)
+			}
+			if !strings.HasPrefix(err.Error(), path+":") {
+				t.Fatalf("error %q does not start with the file path", err)
+			}
+		})
+	}
+}
+
+func TestParseSuiteFileEmpty(t *testing.T) {
+	path := writeSuite(t, t.TempDir(), "empty.yaml", "")
+	_, err := ParseSuiteFile(path)
+	if err == nil || err.Error() != path+": empty suite document" {
+		t.Fatalf("error %v", err)
+	}
+}
+
+func TestParseSuiteFileValues(t *testing.T) {
+	path := writeSuite(t, t.TempDir(), "suite.yaml", `id: x
+Name: Case Insensitive
+timeout: 90s
+environment:
+  postgres:
+    version: 14
+declarativeTests:
+  - name: t
+    delayAfter: 250ms
+    assertions:
+      - database: postgres
+        eventually: {timeout: 2s, interval: 100ms}
+`)
+	ts, err := ParseSuiteFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts.Name != "Case Insensitive" {
+		t.Errorf("name %q", ts.Name)
+	}
+	if ts.Timeout != 90*time.Second {
+		t.Errorf("timeout %s", ts.Timeout)
+	}
+	if v := ts.Environment.Postgres.Version; v != "14" {
+		t.Errorf("postgres version %q", v)
+	}
+	test := ts.DeclarativeTests[0]
+	if test.DelayAfter != 250*time.Millisecond {
+		t.Errorf("delayAfter %s", test.DelayAfter)
+	}
+	if ev := test.Assertions[0].Eventually; ev.Timeout != 2*time.Second || ev.Interval != 100*time.Millisecond {
+		t.Errorf("eventually %+v", ev)
+	}
+}
+
+func TestLoadSuiteFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeSuite(t, dir, "a.yaml", "id: a\n")
+	writeSuite(t, dir, "b.yml", "id: b\nretries: x\n")
+	writeSuite(t, dir, "c.json", `{"id": "c"}`)
+	writeSuite(t, dir, "d.yaml", "id: d\nunknown: 1\n")
+	writeSuite(t, dir, "notes.txt", "not a suite")
+	if err := os.Mkdir(filepath.Join(dir, "nested.yaml"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	suites, err := LoadSuiteFiles(dir)
+	var ids []string
+	for _, ts := range suites {
+		ids = append(ids, ts.ID)
+	}
+	if strings.Join(ids, ",") != "a,c" {
+		t.Fatalf("loaded suites %v, want a,c", ids)
+	}
+	if err == nil {
+		t.Fatal("expected errors for b.yml and d.yaml")
+	}
+	for _, want := range []string{filepath.Join(dir, "b.yml") + ":2:", filepath.Join(dir, "d.yaml") + ":2:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package suite
 
 import (
 	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,9 +11,12 @@ import (
 
 const excludedPrefix = "dolchevideo-"
 
-// Loader discovers microservices and test suites.
+// Loader discovers microservices and test suites. SuiteDir optionally points
+// at a directory of YAML/JSON suite documents loaded next to the suites
+// registered from Go.
 type Loader struct {
-	WorkDir string
+	WorkDir  string
+	SuiteDir string
 }
 
 func NewLoader(workDir string) *Loader {
@@ -42,10 +47,31 @@ func (l *Loader) DiscoverServices() ([]string, error) {
 	return services, nil
 }
 
-// LoadTestSuites returns all registered suites. Suites register themselves via
-// init() functions in their respective packages.
-func (l *Loader) LoadTestSuites() []*TestSuite {
+// LoadTestSuites returns all registered suites followed by the suites parsed
+// from SuiteDir. Go suites register themselves via init() functions in their
+// respective packages. A missing SuiteDir means there are no file suites; any
+// file in it that fails to load is an error.
+func (l *Loader) LoadTestSuites() ([]*TestSuite, error) {
 	suites := RegisteredSuites()
+	if l.SuiteDir != "" {
+		if _, err := os.Stat(l.SuiteDir); err == nil {
+			fileSuites, err := LoadSuiteFiles(l.SuiteDir)
+			if err != nil {
+				return nil, err
+			}
+			suites = append(suites, fileSuites...)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	seen := map[string]bool{}
+	for _, ts := range suites {
+		if seen[ts.ID] {
+			return nil, fmt.Errorf("duplicate suite id %q", ts.ID)
+		}
+		seen[ts.ID] = true
+	}
+
 	services, svcErr := l.DiscoverServices()
 	for _, ts := range suites {
 		if len(ts.Services) == 0 {
@@ -55,7 +81,7 @@ func (l *Loader) LoadTestSuites() []*TestSuite {
 		}
 		ts.Config = mergeMaps(map[string]any{"workdir": filepath.Clean(l.WorkDir)}, ts.Config)
 	}
-	return suites
+	return suites, nil
 }
 
 func mergeMaps(base map[string]any, overrides map[string]any) map[string]any {
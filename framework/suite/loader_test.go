@@ -0,0 +1,39 @@
+package suite
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadTestSuitesMissingSuiteDir(t *testing.T) {
+	l := &Loader{WorkDir: t.TempDir(), SuiteDir: filepath.Join(t.TempDir(), "missing")}
+	suites, err := l.LoadTestSuites()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suites) != len(RegisteredSuites()) {
+		t.Fatalf("loaded %d suites, want only the registered ones", len(suites))
+	}
+}
+
+func TestLoadTestSuitesReportsEveryFileError(t *testing.T) {
+	dir := t.TempDir()
+	writeSuite(t, dir, "a.yaml", "id: a\n")
+	writeSuite(t, dir, "bad.yaml", "id: bad\nretries: x\n")
+	if err := os.Symlink(filepath.Join(dir, "gone.yaml"), filepath.Join(dir, "broken.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	l := &Loader{WorkDir: t.TempDir(), SuiteDir: dir}
+	_, err := l.LoadTestSuites()
+	if err == nil {
+		t.Fatal("expected errors for broken.yaml and bad.yaml")
+	}
+	for _, want := range []string{filepath.Join(dir, "broken.yaml"), filepath.Join(dir, "bad.yaml") + ":2:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/example/go-test-framework/framework/declarative"
 	"github.com/example/go-test-framework/framework/env"
+	"github.com/example/go-test-framework/framework/script"
 )
 
 // ExecutionType controls sequential vs parallel behavior.
@@ -15,23 +16,34 @@ const (
 	ExecutionTypeParallel   ExecutionType = "parallel"
 )
 
-// TestDefinition represents a classic Go/integration test entry point.
+// TestDefinition represents a classic Go/integration test entry point. The
+// tests run with `go test` inside the service directory; Packages, Tags and
+// Run narrow what gets executed. Definitions of type "script" run Script
+// instead.
 type TestDefinition struct {
-	Service string `json:"service"`
-	Type    string `json:"type"`
+	Service  string       `json:"service"`
+	Type     string       `json:"type"`
+	Packages []string     `json:"packages"`
+	Tags     []string     `json:"tags"`
+	Run      string       `json:"run"`
+	Script   *script.Spec `json:"script"`
 }
 
-// TestSuite describes infra requirements, tests, retries, etc.
+// TestSuite describes infra requirements, tests, retries, etc. Sequential
+// suites stop at the first failing test unless ContinueOnFailure is set;
+// parallel suites run at most MaxParallel tests at once when it is positive.
 type TestSuite struct {
-	ID               string                        `json:"id"`
-	Name             string                        `json:"name"`
-	Services         []string                      `json:"services"`
-	Dependencies     []string                      `json:"dependencies"`
-	ExecutionType    ExecutionType                 `json:"executionType"`
-	Timeout          time.Duration                 `json:"timeout"`
-	Retries          int                           `json:"retries"`
-	Tests            []TestDefinition              `json:"tests"`
-	DeclarativeTests []declarative.DeclarativeTest `json:"declarativeTests"`
-	Environment      env.EnvironmentConfig         `json:"environment"`
-	Config           map[string]any                `json:"config"`
+	ID                string                        `json:"id"`
+	Name              string                        `json:"name"`
+	Services          []string                      `json:"services"`
+	Dependencies      []string                      `json:"dependencies"`
+	ExecutionType     ExecutionType                 `json:"executionType"`
+	MaxParallel       int                           `json:"maxParallel"`
+	Timeout           time.Duration                 `json:"timeout"`
+	Retries           int                           `json:"retries"`
+	ContinueOnFailure bool                          `json:"continueOnFailure"`
+	Tests             []TestDefinition              `json:"tests"`
+	DeclarativeTests  []declarative.DeclarativeTest `json:"declarativeTests"`
+	Environment       env.EnvironmentConfig         `json:"environment"`
+	Config            map[string]any                `json:"config"`
 }
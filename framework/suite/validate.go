@@ -0,0 +1,170 @@
+package suite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/example/go-test-framework/framework/db/postgres"
+	"github.com/example/go-test-framework/framework/declarative"
+	httpclient "github.com/example/go-test-framework/framework/http"
+)
+
+var httpMethods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true,
+	"DELETE": true, "OPTIONS": true,
+}
+
+// Problem is a defect found by Validate. Location names the suite, test,
+// step and assertion it was found in.
+type Problem struct {
+	Location string
+	Msg      string
+}
+
+func (p Problem) String() string {
+	return p.Location + ": " + p.Msg
+}
+
+// ValidationError lists every problem found in a set of suites.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d suite problem(s):", len(e.Problems))
+	for _, p := range e.Problems {
+		b.WriteString("\n  ")
+		b.WriteString(p.String())
+	}
+	return b.String()
+}
+
+// Validate checks the declarative tests of every suite before anything runs:
+// assertion targets, HTTP methods and services, which must be resolvable by
+// resolver. It returns a *ValidationError listing all problems, or nil.
+func Validate(suites []*TestSuite, resolver httpclient.ServiceResolver) error {
+	v := &validator{resolver: resolver}
+	for _, ts := range suites {
+		for i, test := range ts.DeclarativeTests {
+			loc := fmt.Sprintf("suite %s, declarativeTests[%d]", ts.ID, i)
+			if test.Name != "" {
+				loc = fmt.Sprintf("suite %s, test %q", ts.ID, test.Name)
+			}
+			v.test(loc, test)
+		}
+	}
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+type validator struct {
+	resolver httpclient.ServiceResolver
+	problems []Problem
+}
+
+func (v *validator) addf(loc, format string, args ...any) {
+	v.problems = append(v.problems, Problem{Location: loc, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) test(loc string, test declarative.DeclarativeTest) {
+	steps, err := test.ExecutionSteps()
+	if err != nil {
+		v.addf(loc, "%v", err)
+		steps = test.Steps
+	}
+	for i, step := range steps {
+		stepLoc := loc
+		if len(test.Steps) > 0 {
+			stepLoc = fmt.Sprintf("%s, step #%d", loc, i+1)
+			if step.Name != "" {
+				stepLoc = fmt.Sprintf("%s, step #%d %q", loc, i+1, step.Name)
+			}
+		}
+		switch {
+		case step.Script != nil:
+		case step.Publish != nil:
+			if step.Publish.Exchange == "" && step.Publish.RoutingKey == "" {
+				v.addf(stepLoc, "publish needs an exchange or routing key")
+			}
+		case step.Produce != nil:
+			if step.Produce.Topic == "" {
+				v.addf(stepLoc, "produce topic is required")
+			}
+		default:
+			v.action(stepLoc, step.Action)
+		}
+		for j, assertion := range step.Assertions {
+			v.assertion(fmt.Sprintf("%s, assertions[%d]", stepLoc, j), assertion)
+		}
+		for j, m := range step.Messages {
+			if m.Exchange == "" {
+				v.addf(fmt.Sprintf("%s, messages[%d]", stepLoc, j), "exchange is required; queues cannot bind to the default exchange")
+			}
+		}
+		for j, r := range step.Records {
+			if r.Topic == "" {
+				v.addf(fmt.Sprintf("%s, records[%d]", stepLoc, j), "topic is required")
+			}
+		}
+	}
+}
+
+func (v *validator) action(loc string, action declarative.Action) {
+	if method := strings.ToUpper(action.Method); method != "" && !httpMethods[method] {
+		v.addf(loc, "unknown HTTP method %q", action.Method)
+	}
+	if action.Service == "" {
+		v.addf(loc, "action service is required")
+		return
+	}
+	if v.resolver == nil {
+		return
+	}
+	if _, err := v.resolver.Resolve(action.Service); err != nil {
+		v.addf(loc, "unresolved service %q: %v", action.Service, err)
+	}
+}
+
+func (v *validator) assertion(loc string, a declarative.Assertion) {
+	switch strings.ToLower(a.Database) {
+	case "postgres", "postgresql":
+		if a.SQL != "" {
+			if a.Table != "" || len(a.Query) > 0 {
+				v.addf(loc, "postgres assertion sets both sql and table/query")
+			}
+		} else if a.Table == "" {
+			v.addf(loc, "postgres assertion needs a table or sql")
+		} else if _, err := postgres.BuildSelect(postgres.Select{Table: a.Table, Filters: a.Query, OrderBy: a.OrderBy, Limit: a.Limit}); err != nil {
+			v.addf(loc, "postgres query: %v", err)
+		}
+	case "mongodb", "mongo":
+		if a.Collection == "" {
+			v.addf(loc, "mongodb assertion needs a collection")
+		}
+		if len(a.Pipeline) > 0 && len(a.Query) > 0 {
+			v.addf(loc, "mongodb assertion sets both pipeline and query")
+		}
+		if a.DatabaseName == "" && a.Schema == "" {
+			v.addf(loc, "mongodb assertion needs a databaseName")
+		}
+	case "redis":
+		switch {
+		case a.Key == "" && a.Pattern == "":
+			v.addf(loc, "redis assertion needs a key or pattern")
+		case a.Key == "" && a.Expected.Count == nil:
+			v.addf(loc, "redis pattern assertion needs expected.count")
+		}
+	case "":
+		v.addf(loc, "assertion database is required")
+	default:
+		v.addf(loc, "unknown assertion database %q (want postgres, mongodb or redis)", a.Database)
+	}
+	if spec, ok := a.Expected.RowSpec(nil); ok {
+		if err := spec.Validate(); err != nil {
+			v.addf(loc, "expected: %v", err)
+		}
+	}
+}
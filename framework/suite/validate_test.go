@@ -0,0 +1,187 @@
+package suite
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/example/go-test-framework/framework/declarative"
+)
+
+type knownServices []string
+
+func (k knownServices) Resolve(service string) (string, error) {
+	for _, s := range k {
+		if s == service {
+			return "http://" + service, nil
+		}
+	}
+	return "", fmt.Errorf("no address for %s", service)
+}
+
+func TestValidate(t *testing.T) {
+	action := declarative.Action{Service: "bonus-service", Endpoint: "/api/bonuses", Method: "POST"}
+	one := 1
+	tests := []struct {
+		name string
+		test declarative.DeclarativeTest
+		want []Problem
+	}{
+		{
+			name: "valid test",
+			test: declarative.DeclarativeTest{Name: "ok", Action: action, Assertions: []declarative.Assertion{
+				{Database: "postgres", Table: "bonus_wallets", Query: map[string]any{"user_id": 1}},
+				{Database: "postgres", SQL: "SELECT 1"},
+				{Database: "mongodb", DatabaseName: "payments", Collection: "bonuses"},
+				{Database: "redis", Key: "bonus:1"},
+				{Database: "redis", Pattern: "bonus:*", Expected: declarative.ExpectedResult{Count: &one}},
+			}},
+		},
+		{
+			name: "unknown database",
+			test: declarative.DeclarativeTest{Name: "t", Action: action, Assertions: []declarative.Assertion{{Database: "mysql", Table: "t"}}},
+			want: []Problem{{Location: `suite s, test "t", assertions[0]`, Msg: `unknown assertion database "mysql" (want postgres, mongodb or redis)`}},
+		},
+		{
+			name: "missing database",
+			test: declarative.DeclarativeTest{Name: "t", Action: action, Assertions: []declarative.Assertion{{Table: "t"}}},
+			want: []Problem{{Location: `suite s, test "t", assertions[0]`, Msg: "assertion database is required"}},
+		},
+		{
+			name: "missing table",
+			test: declarative.DeclarativeTest{Name: "t", Action: action, Assertions: []declarative.Assertion{{Database: "postgres"}}},
+			want: []Problem{{Location: `suite s, test "t", assertions[0]`, Msg: "postgres assertion needs a table or sql"}},
+		},
+		{
+			name: "sql and table",
+			test: declarative.DeclarativeTest{Name: "t", Action: action, Assertions: []declarative.Assertion{{Database: "postgres", SQL: "SELECT 1", Table: "t"}}},
+			want: []Problem{{Location: `suite s, test "t", assertions[0]`, Msg: "postgres assertion sets both sql and table/query"}},
+		},
+		{
+			name: "invalid postgres query",
+			test: declarative.DeclarativeTest{Name: "t", Action: action, Assertions: []declarative.Assertion{{Database: "postgres", Table: "t", Query: map[string]any{"a": map[string]any{"$between": 1}}}}},
+			want: []Problem{{Location: `suite s, test "t", assertions[0]`, Msg: "postgres query: column a: unsupported operator $between"}},
+		},
+		{
+			name: "missing collection and database name",
+			test: declarative.DeclarativeTest{Name: "t", Action: action, Assertions: []declarative.Assertion{{Database: "mongodb"}}},
+			want: []Problem{
+				{Location: `suite s, test "t", assertions[0]`, Msg: "mongodb assertion needs a collection"},
+				{Location: `suite s, test "t", assertions[0]`, Msg: "mongodb assertion needs a databaseName"},
+			},
+		},
+		{
+			name: "missing redis key",
+			test: declarative.DeclarativeTest{Name: "t", Action: action, Assertions: []declarative.Assertion{{Database: "redis"}}},
+			want: []Problem{{Location: `suite s, test "t", assertions[0]`, Msg: "redis assertion needs a key or pattern"}},
+		},
+		{
+			name: "redis pattern without count",
+			test: declarative.DeclarativeTest{Name: "t", Action: action, Assertions: []declarative.Assertion{{Database: "redis", Pattern: "bonus:*"}}},
+			want: []Problem{{Location: `suite s, test "t", assertions[0]`, Msg: "redis pattern assertion needs expected.count"}},
+		},
+		{
+			name: "invalid row spec",
+			test: declarative.DeclarativeTest{Name: "t", Action: action, Assertions: []declarative.Assertion{{Database: "postgres", Table: "t", Expected: declarative.ExpectedResult{Match: "first"}}}},
+			want: []Problem{{Location: `suite s, test "t", assertions[0]`, Msg: `expected: unknown row match mode "first" (want anyRowMatches, allRowsMatch or exactlyRows)`}},
+		},
+		{
+			name: "bad HTTP method",
+			test: declarative.DeclarativeTest{Name: "t", Action: declarative.Action{Service: "bonus-service", Method: "FETCH"}},
+			want: []Problem{{Location: `suite s, test "t"`, Msg: `unknown HTTP method "FETCH"`}},
+		},
+		{
+			name: "lower-case HTTP method",
+			test: declarative.DeclarativeTest{Name: "t", Action: declarative.Action{Service: "bonus-service", Method: "get"}},
+		},
+		{
+			name: "missing service",
+			test: declarative.DeclarativeTest{Name: "t", Action: declarative.Action{Endpoint: "/"}},
+			want: []Problem{{Location: `suite s, test "t"`, Msg: "action service is required"}},
+		},
+		{
+			name: "unresolvable service",
+			test: declarative.DeclarativeTest{Name: "t", Action: declarative.Action{Service: "wallet-service"}},
+			want: []Problem{{Location: `suite s, test "t"`, Msg: `unresolved service "wallet-service": no address for wallet-service`}},
+		},
+		{
+			name: "unnamed test",
+			test: declarative.DeclarativeTest{Action: declarative.Action{Service: "wallet-service"}},
+			want: []Problem{{Location: "suite s, declarativeTests[0]", Msg: `unresolved service "wallet-service": no address for wallet-service`}},
+		},
+		{
+			name: "step locations",
+			test: declarative.DeclarativeTest{Name: "t", Steps: []declarative.Step{
+				{Name: "create", Action: action},
+				{Action: action, Assertions: []declarative.Assertion{{Database: "postgres"}}},
+				{Name: "publish", Publish: &declarative.PublishAction{}, Messages: []declarative.MessageAssertion{{RoutingKey: "bonus.created"}}},
+				{Name: "produce", Produce: &declarative.ProduceAction{}, Records: []declarative.RecordAssertion{{}}},
+			}},
+			want: []Problem{
+				{Location: `suite s, test "t", step #2, assertions[0]`, Msg: "postgres assertion needs a table or sql"},
+				{Location: `suite s, test "t", step #3 "publish"`, Msg: "publish needs an exchange or routing key"},
+				{Location: `suite s, test "t", step #3 "publish", messages[0]`, Msg: "exchange is required; queues cannot bind to the default exchange"},
+				{Location: `suite s, test "t", step #4 "produce"`, Msg: "produce topic is required"},
+				{Location: `suite s, test "t", step #4 "produce", records[0]`, Msg: "topic is required"},
+			},
+		},
+		{
+			name: "steps mixed with top-level fields",
+			test: declarative.DeclarativeTest{Name: "t", Action: action, Steps: []declarative.Step{{Action: action}}},
+			want: []Problem{{Location: `suite s, test "t"`, Msg: "steps cannot be combined with top-level action"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := &TestSuite{ID: "s", DeclarativeTests: []declarative.DeclarativeTest{tt.test}}
+			err := Validate([]*TestSuite{ts}, knownServices{"bonus-service"})
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("error %v (%T), want *ValidationError", err, err)
+			}
+			if !reflect.DeepEqual(verr.Problems, tt.want) {
+				t.Fatalf("problems\n%v\nwant\n%v", verr.Problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCollectsAllSuites(t *testing.T) {
+	suites := []*TestSuite{
+		{ID: "a", DeclarativeTests: []declarative.DeclarativeTest{{Name: "one", Action: declarative.Action{Service: "x"}}}},
+		{ID: "b"},
+		{ID: "c", DeclarativeTests: []declarative.DeclarativeTest{
+			{Name: "two", Action: declarative.Action{Service: "bonus-service", Method: "SEND"}},
+			{Name: "three", Action: declarative.Action{Service: "bonus-service"}, Assertions: []declarative.Assertion{{Database: "oracle"}}},
+		}},
+	}
+	err := Validate(suites, knownServices{"bonus-service"})
+	want := `3 suite problem(s):
+  suite a, test "one": unresolved service "x": no address for x
+  suite c, test "two": unknown HTTP method "SEND"
+  suite c, test "three", assertions[0]: unknown assertion database "oracle" (want postgres, mongodb or redis)`
+	if err == nil || err.Error() != want {
+		t.Fatalf("error\n%v\nwant\n%s", err, want)
+	}
+}
+
+func TestValidateWithoutResolver(t *testing.T) {
+	ts := &TestSuite{ID: "s", DeclarativeTests: []declarative.DeclarativeTest{{Action: declarative.Action{Service: "anything"}}}}
+	if err := Validate([]*TestSuite{ts}, nil); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if err := Validate(nil, nil); err != nil {
+		t.Fatalf("Validate(nil): %v", err)
+	}
+	if !strings.Contains((&ValidationError{Problems: []Problem{{Location: "l", Msg: "m"}}}).Error(), "\n  l: m") {
+		t.Fatal("ValidationError does not list problems")
+	}
+}
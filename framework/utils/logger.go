@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -8,10 +9,13 @@ import (
 	"time"
 )
 
+// outputMu serialises writes from every logger so lines from concurrently
+// running suites never interleave.
+var outputMu sync.Mutex
+
 // StructuredLogger prints log entries with a consistent structure so that
 // runners and developers can trace execution across suites and steps.
 type StructuredLogger struct {
-	mu     sync.Mutex
 	fields map[string]string
 }
 
@@ -42,9 +46,6 @@ func (l *StructuredLogger) Error(message string, fields map[string]any) {
 }
 
 func (l *StructuredLogger) write(level, message string, fields map[string]any) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-
 	entry := map[string]any{
 		"level":   level,
 		"message": message,
@@ -58,6 +59,8 @@ func (l *StructuredLogger) write(level, message string, fields map[string]any) {
 	}
 
 	data, err := json.Marshal(entry)
+	outputMu.Lock()
+	defer outputMu.Unlock()
 	if err != nil {
 		fmt.Fprintf(os.Stdout, "level=%s message=%s error=%v\n", level, message, err)
 		return
@@ -65,3 +68,23 @@ func (l *StructuredLogger) write(level, message string, fields map[string]any) {
 
 	fmt.Fprintln(os.Stdout, string(data))
 }
+
+type loggerKey struct{}
+
+// ContextWithLogger attaches a logger so code running on behalf of a suite
+// keeps its fields (suite id, test name) without sharing mutable state.
+func ContextWithLogger(ctx context.Context, l *StructuredLogger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// LoggerFromContext returns the logger attached to ctx, falling back to
+// fallback and finally to a fresh logger.
+func LoggerFromContext(ctx context.Context, fallback *StructuredLogger) *StructuredLogger {
+	if l, ok := ctx.Value(loggerKey{}).(*StructuredLogger); ok && l != nil {
+		return l
+	}
+	if fallback != nil {
+		return fallback
+	}
+	return NewLogger()
+}
@@ -55,6 +55,19 @@ func substituteString(in string, vars map[string]string) string {
 	})
 }
 
+// SubstituteJSON replaces ${var} placeholders in a JSON document, escaping
+// the values so they stay valid inside JSON strings. Key order is preserved.
+func SubstituteJSON(doc json.RawMessage, vars map[string]string) json.RawMessage {
+	return variablePattern.ReplaceAllFunc(doc, func(match []byte) []byte {
+		val, ok := vars[string(variablePattern.FindSubmatch(match)[1])]
+		if !ok {
+			return match
+		}
+		quoted, _ := json.Marshal(val)
+		return quoted[1 : len(quoted)-1]
+	})
+}
+
 // CloneMap returns a deep copy of an arbitrary map; used for building
 // request payloads where we must avoid mutating original suite definitions.
 func CloneMap(in map[string]any) map[string]any {
@@ -49,14 +49,13 @@ func init() {
 						},
 					},
 				},
-				DelayAfter: 500 * time.Millisecond,
 				Assertions: []declarative.Assertion{
 					{
 						Database:     "mongodb",
 						DatabaseName: "payments",
 						Collection:   "bonuses",
 						Query: map[string]any{
-							"_id":    "${bonusId}",
+							"_id":    map[string]any{"$oid": "${bonusId}"},
 							"userId": 1,
 						},
 						Expected: declarative.ExpectedResult{
@@ -67,6 +66,7 @@ func init() {
 								"status": "active",
 							},
 						},
+						Eventually: &declarative.Eventually{Timeout: 5 * time.Second, Interval: 100 * time.Millisecond, Backoff: 2},
 					},
 					{
 						Database: "postgres",
@@ -84,6 +84,7 @@ func init() {
 								"initial_amount": 100,
 							},
 						},
+						Eventually: &declarative.Eventually{Timeout: 5 * time.Second, Interval: 100 * time.Millisecond, Backoff: 2},
 					},
 				},
 			},